@@ -0,0 +1,207 @@
+package cognito
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+)
+
+const userAttributesPrefix = "userAttributes."
+
+// Challenge holds typed parameters of the challenge returned by SignIn or RespondToChallenge.
+type Challenge struct {
+	Name    string
+	Session *string
+	// Username is the name Cognito expects in responses, it may differ from the one used to sign in, e.g. for aliases,
+	// and device challenges have to be answered with it
+	Username                string
+	CodeDeliveryDestination string
+	MFAsCanChoose           []string
+	MFAsCanSetup            []string
+	RequiredAttributes      []string
+	UserAttributes          map[string]string
+	Parameters              map[string]*string
+}
+
+// ChallengeResponse is an answer to one of the supported challenges.
+type ChallengeResponse interface {
+	challengeName() string
+	challengeResponses() map[string]*string
+}
+
+type SMSMFAResponse struct {
+	Code string
+}
+
+type SoftwareTokenMFAResponse struct {
+	Code string
+}
+
+// SelectMFATypeResponse chooses one of MFAsCanChoose, either SMS_MFA or SOFTWARE_TOKEN_MFA.
+type SelectMFATypeResponse struct {
+	MFAType string
+}
+
+// MFASetupResponse completes MFA_SETUP, it has to be sent with the session returned by software token verification.
+type MFASetupResponse struct{}
+
+type CustomChallengeResponse struct {
+	Answer string
+}
+
+// NewPasswordResponse sets the permanent password, Attributes have to contain all RequiredAttributes of the challenge.
+type NewPasswordResponse struct {
+	NewPassword string
+	Attributes  map[string]string
+}
+
+func (SMSMFAResponse) challengeName() string {
+	return cognitoidentityprovider.ChallengeNameTypeSmsMfa
+}
+
+func (r SMSMFAResponse) challengeResponses() map[string]*string {
+	return map[string]*string{"SMS_MFA_CODE": &r.Code}
+}
+
+func (SoftwareTokenMFAResponse) challengeName() string {
+	return cognitoidentityprovider.ChallengeNameTypeSoftwareTokenMfa
+}
+
+func (r SoftwareTokenMFAResponse) challengeResponses() map[string]*string {
+	return map[string]*string{"SOFTWARE_TOKEN_MFA_CODE": &r.Code}
+}
+
+func (SelectMFATypeResponse) challengeName() string {
+	return cognitoidentityprovider.ChallengeNameTypeSelectMfaType
+}
+
+func (r SelectMFATypeResponse) challengeResponses() map[string]*string {
+	return map[string]*string{"ANSWER": &r.MFAType}
+}
+
+func (MFASetupResponse) challengeName() string {
+	return cognitoidentityprovider.ChallengeNameTypeMfaSetup
+}
+
+func (MFASetupResponse) challengeResponses() map[string]*string {
+	return map[string]*string{}
+}
+
+func (CustomChallengeResponse) challengeName() string {
+	return cognitoidentityprovider.ChallengeNameTypeCustomChallenge
+}
+
+func (r CustomChallengeResponse) challengeResponses() map[string]*string {
+	return map[string]*string{"ANSWER": &r.Answer}
+}
+
+func (NewPasswordResponse) challengeName() string {
+	return cognitoidentityprovider.ChallengeNameTypeNewPasswordRequired
+}
+
+func (r NewPasswordResponse) challengeResponses() map[string]*string {
+	responses := map[string]*string{"NEW_PASSWORD": &r.NewPassword}
+	for name, value := range r.Attributes {
+		value := value
+		responses[userAttributesPrefix+name] = &value
+	}
+	return responses
+}
+
+// RespondToChallenge answers the challenge identified by session, the result carries either tokens or the next
+// challenge, so calls can be chained until tokens are issued.
+func (ca *Adapter) RespondToChallenge(username string, session *string, response ChallengeResponse) (*SignInResult, error) {
+
+	challengeName := response.challengeName()
+	responses := response.challengeResponses()
+	responses["USERNAME"] = &username
+	if err := ca.addSecretHash(responses, username); err != nil {
+		return nil, err
+	}
+
+	input := &cognitoidentityprovider.AdminRespondToAuthChallengeInput{
+		ChallengeName:      &challengeName,
+		ChallengeResponses: responses,
+		ClientId:           &ca.clientID,
+		UserPoolId:         &ca.poolID,
+		Session:            session,
+	}
+
+	output, err := ca.provider.AdminRespondToAuthChallenge(input)
+	if err != nil {
+		return nil, wrapErr(err, "error in cognito.Adapter while sending AdminRespondToAuthChallengeRequest")
+	}
+
+	return &SignInResult{
+		AuthenticationResult: toAuthenticationResult(output.AuthenticationResult),
+		ChallengeName:        output.ChallengeName,
+		ChallengeParameters:  output.ChallengeParameters,
+		Session:              output.Session,
+	}, nil
+}
+
+// Challenge returns typed details of the pending challenge, nil when tokens were issued.
+func (r *SignInResult) Challenge() *Challenge {
+	if r.ChallengeName == nil || *r.ChallengeName == "" {
+		return nil
+	}
+
+	params := r.ChallengeParameters
+	challenge := &Challenge{
+		Name:                    *r.ChallengeName,
+		Session:                 r.Session,
+		Username:                firstParameter(params, "USER_ID_FOR_SRP", "USERNAME"),
+		CodeDeliveryDestination: parameter(params, "CODE_DELIVERY_DESTINATION"),
+		MFAsCanChoose:           jsonList(parameter(params, "MFAS_CAN_CHOOSE")),
+		MFAsCanSetup:            jsonList(parameter(params, "MFAS_CAN_SETUP")),
+		Parameters:              params,
+	}
+
+	for _, attr := range jsonList(parameter(params, "requiredAttributes")) {
+		challenge.RequiredAttributes = append(challenge.RequiredAttributes, strings.TrimPrefix(attr, userAttributesPrefix))
+	}
+	if attrs := parameter(params, "userAttributes"); attrs != "" {
+		_ = json.Unmarshal([]byte(attrs), &challenge.UserAttributes)
+	}
+	return challenge
+}
+
+func toAuthenticationResult(result *cognitoidentityprovider.AuthenticationResultType) *AuthenticationResult {
+	if result == nil {
+		return nil
+	}
+
+	return &AuthenticationResult{
+		AccessToken:  result.AccessToken,
+		ExpiresIn:    result.ExpiresIn,
+		IDToken:      result.IdToken,
+		RefreshToken: result.RefreshToken,
+		TokenType:    result.TokenType,
+	}
+}
+
+func parameter(params map[string]*string, name string) string {
+	if v := params[name]; v != nil {
+		return *v
+	}
+	return ""
+}
+
+func firstParameter(params map[string]*string, names ...string) string {
+	for _, name := range names {
+		if v := parameter(params, name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// jsonList decodes challenge parameters holding JSON arrays, e.g. ["SMS_MFA","SOFTWARE_TOKEN_MFA"].
+func jsonList(value string) []string {
+	var list []string
+	if value != "" {
+		_ = json.Unmarshal([]byte(value), &list)
+	}
+	return list
+}
@@ -0,0 +1,139 @@
+package cognito
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	cip "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdapter_RespondToChallenge_responses(t *testing.T) {
+
+	// given
+	var testData = []struct {
+		response          ChallengeResponse
+		expectedName      string
+		expectedResponses map[string]string
+	}{
+		{SMSMFAResponse{Code: "123456"}, cip.ChallengeNameTypeSmsMfa,
+			map[string]string{"USERNAME": username, "SMS_MFA_CODE": "123456"}},
+		{SoftwareTokenMFAResponse{Code: "654321"}, cip.ChallengeNameTypeSoftwareTokenMfa,
+			map[string]string{"USERNAME": username, "SOFTWARE_TOKEN_MFA_CODE": "654321"}},
+		{SelectMFATypeResponse{MFAType: cip.ChallengeNameTypeSoftwareTokenMfa}, cip.ChallengeNameTypeSelectMfaType,
+			map[string]string{"USERNAME": username, "ANSWER": cip.ChallengeNameTypeSoftwareTokenMfa}},
+		{MFASetupResponse{}, cip.ChallengeNameTypeMfaSetup,
+			map[string]string{"USERNAME": username}},
+		{CustomChallengeResponse{Answer: "42"}, cip.ChallengeNameTypeCustomChallenge,
+			map[string]string{"USERNAME": username, "ANSWER": "42"}},
+		{NewPasswordResponse{NewPassword: newPassword, Attributes: map[string]string{emailAttr: email}}, cip.ChallengeNameTypeNewPasswordRequired,
+			map[string]string{"USERNAME": username, "NEW_PASSWORD": newPassword, "userAttributes.email": email}},
+		{DeviceSRPAuthResponse{DeviceKey: "device-key", SRPA: "abc"}, cip.ChallengeNameTypeDeviceSrpAuth,
+			map[string]string{"USERNAME": username, "DEVICE_KEY": "device-key", "SRP_A": "abc"}},
+		{DevicePasswordVerifierResponse{DeviceKey: "device-key", SecretBlock: "block", Timestamp: "now", Signature: "sig"},
+			cip.ChallengeNameTypeDevicePasswordVerifier, map[string]string{"USERNAME": username, "DEVICE_KEY": "device-key",
+				"PASSWORD_CLAIM_SECRET_BLOCK": "block", "TIMESTAMP": "now", "PASSWORD_CLAIM_SIGNATURE": "sig"}},
+	}
+
+	for _, data := range testData {
+
+		provider := &providerMock{
+			respondToAuthChallengeOutput: &cip.AdminRespondToAuthChallengeOutput{},
+		}
+		adapter := NewTestAdapter(provider)
+
+		// when
+		_, err := adapter.RespondToChallenge(username, &session, data.response)
+
+		// then
+		assert.NoError(t, err)
+		input := provider.respondToAuthChallengeInput
+		assert.Equal(t, data.expectedName, aws.StringValue(input.ChallengeName))
+		assert.Equal(t, data.expectedResponses, aws.StringValueMap(input.ChallengeResponses))
+		assert.Equal(t, &session, input.Session)
+	}
+}
+
+func TestAdapter_RespondToChallenge_chained(t *testing.T) {
+
+	// given
+	nextChallenge := cip.ChallengeNameTypeSoftwareTokenMfa
+	nextSession := "next-session-id"
+	provider := &providerMock{
+		respondToAuthChallengeOutput: &cip.AdminRespondToAuthChallengeOutput{
+			ChallengeName: &nextChallenge,
+			Session:       &nextSession,
+		},
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	result, err := adapter.RespondToChallenge(username, &session, SelectMFATypeResponse{MFAType: nextChallenge})
+
+	// then
+	assert.NoError(t, err)
+	assert.Nil(t, result.AuthenticationResult)
+	assert.Equal(t, nextChallenge, result.Challenge().Name)
+	assert.Equal(t, &nextSession, result.Challenge().Session)
+}
+
+func TestAdapter_RespondToChallenge_tokens(t *testing.T) {
+
+	// given
+	accessToken := "access-token"
+	provider := &providerMock{
+		respondToAuthChallengeOutput: &cip.AdminRespondToAuthChallengeOutput{
+			AuthenticationResult: &cip.AuthenticationResultType{AccessToken: &accessToken},
+		},
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	result, err := adapter.RespondToChallenge(username, &session, SoftwareTokenMFAResponse{Code: "123456"})
+
+	// then
+	assert.NoError(t, err)
+	assert.Nil(t, result.Challenge())
+	assert.Equal(t, &accessToken, result.AuthenticationResult.AccessToken)
+}
+
+func TestAdapter_RespondToChallenge_error(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		respondToAuthChallengeErr: errors.New("error while sending respond to auth challenge request"),
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	result, err := adapter.RespondToChallenge(username, &session, SMSMFAResponse{Code: "123456"})
+
+	// then
+	assert.Error(t, err)
+	assert.Nil(t, result)
+}
+
+func TestSignInResult_Challenge_parameters(t *testing.T) {
+
+	// given
+	challengeName := cip.ChallengeNameTypeNewPasswordRequired
+	result := &SignInResult{
+		ChallengeName: &challengeName,
+		ChallengeParameters: map[string]*string{
+			"USER_ID_FOR_SRP":    aws.String("user-sub"),
+			"MFAS_CAN_CHOOSE":    aws.String(`["SMS_MFA","SOFTWARE_TOKEN_MFA"]`),
+			"requiredAttributes": aws.String(`["userAttributes.email"]`),
+			"userAttributes":     aws.String(`{"email":"john@example.com"}`),
+		},
+	}
+
+	// when
+	challenge := result.Challenge()
+
+	// then
+	assert.Equal(t, "user-sub", challenge.Username)
+	assert.Equal(t, []string{"SMS_MFA", "SOFTWARE_TOKEN_MFA"}, challenge.MFAsCanChoose)
+	assert.Equal(t, []string{emailAttr}, challenge.RequiredAttributes)
+	assert.Equal(t, map[string]string{emailAttr: email}, challenge.UserAttributes)
+}
@@ -3,7 +3,9 @@ package cognito
 import (
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/pkg/errors"
 
 	"github.com/Ryanair/goaws"
 )
@@ -58,23 +60,58 @@ type provider interface {
 	ChangePassword(*cognitoidentityprovider.ChangePasswordInput) (*cognitoidentityprovider.ChangePasswordOutput, error)
 	AdminUserGlobalSignOut(*cognitoidentityprovider.AdminUserGlobalSignOutInput) (*cognitoidentityprovider.AdminUserGlobalSignOutOutput, error)
 	AdminResetUserPassword(input *cognitoidentityprovider.AdminResetUserPasswordInput) (*cognitoidentityprovider.AdminResetUserPasswordOutput, error)
+	GlobalSignOut(*cognitoidentityprovider.GlobalSignOutInput) (*cognitoidentityprovider.GlobalSignOutOutput, error)
+	SignUp(*cognitoidentityprovider.SignUpInput) (*cognitoidentityprovider.SignUpOutput, error)
+	ConfirmSignUp(*cognitoidentityprovider.ConfirmSignUpInput) (*cognitoidentityprovider.ConfirmSignUpOutput, error)
+	ResendConfirmationCode(*cognitoidentityprovider.ResendConfirmationCodeInput) (*cognitoidentityprovider.ResendConfirmationCodeOutput, error)
+	ForgotPassword(*cognitoidentityprovider.ForgotPasswordInput) (*cognitoidentityprovider.ForgotPasswordOutput, error)
+	ListUsers(*cognitoidentityprovider.ListUsersInput) (*cognitoidentityprovider.ListUsersOutput, error)
+	AdminGetUser(*cognitoidentityprovider.AdminGetUserInput) (*cognitoidentityprovider.AdminGetUserOutput, error)
+	AdminUpdateUserAttributes(*cognitoidentityprovider.AdminUpdateUserAttributesInput) (*cognitoidentityprovider.AdminUpdateUserAttributesOutput, error)
+	AdminDeleteUserAttributes(*cognitoidentityprovider.AdminDeleteUserAttributesInput) (*cognitoidentityprovider.AdminDeleteUserAttributesOutput, error)
+	AdminDisableUser(*cognitoidentityprovider.AdminDisableUserInput) (*cognitoidentityprovider.AdminDisableUserOutput, error)
+	AdminEnableUser(*cognitoidentityprovider.AdminEnableUserInput) (*cognitoidentityprovider.AdminEnableUserOutput, error)
+	AdminDeleteUser(*cognitoidentityprovider.AdminDeleteUserInput) (*cognitoidentityprovider.AdminDeleteUserOutput, error)
+	AdminSetUserPassword(*cognitoidentityprovider.AdminSetUserPasswordInput) (*cognitoidentityprovider.AdminSetUserPasswordOutput, error)
+	CreateGroup(*cognitoidentityprovider.CreateGroupInput) (*cognitoidentityprovider.CreateGroupOutput, error)
+	GetGroup(*cognitoidentityprovider.GetGroupInput) (*cognitoidentityprovider.GetGroupOutput, error)
+	UpdateGroup(*cognitoidentityprovider.UpdateGroupInput) (*cognitoidentityprovider.UpdateGroupOutput, error)
+	DeleteGroup(*cognitoidentityprovider.DeleteGroupInput) (*cognitoidentityprovider.DeleteGroupOutput, error)
+	ListGroups(*cognitoidentityprovider.ListGroupsInput) (*cognitoidentityprovider.ListGroupsOutput, error)
+	AdminAddUserToGroup(*cognitoidentityprovider.AdminAddUserToGroupInput) (*cognitoidentityprovider.AdminAddUserToGroupOutput, error)
+	AdminRemoveUserFromGroup(*cognitoidentityprovider.AdminRemoveUserFromGroupInput) (*cognitoidentityprovider.AdminRemoveUserFromGroupOutput, error)
+	AdminListGroupsForUser(*cognitoidentityprovider.AdminListGroupsForUserInput) (*cognitoidentityprovider.AdminListGroupsForUserOutput, error)
+	ListUsersInGroup(*cognitoidentityprovider.ListUsersInGroupInput) (*cognitoidentityprovider.ListUsersInGroupOutput, error)
+	AssociateSoftwareToken(*cognitoidentityprovider.AssociateSoftwareTokenInput) (*cognitoidentityprovider.AssociateSoftwareTokenOutput, error)
+	VerifySoftwareToken(*cognitoidentityprovider.VerifySoftwareTokenInput) (*cognitoidentityprovider.VerifySoftwareTokenOutput, error)
+	SetUserMFAPreference(*cognitoidentityprovider.SetUserMFAPreferenceInput) (*cognitoidentityprovider.SetUserMFAPreferenceOutput, error)
+	AdminSetUserMFAPreference(*cognitoidentityprovider.AdminSetUserMFAPreferenceInput) (*cognitoidentityprovider.AdminSetUserMFAPreferenceOutput, error)
 }
 
 type Adapter struct {
-	poolID   string
-	clientID string
-	provider provider
+	poolID       string
+	clientID     string
+	clientSecret string
+	provider     provider
 }
 
-func NewAdapter(cfg *goaws.Config, poolID, clientID string) *Adapter {
-
-	provider := cognitoidentityprovider.New(cfg.Provider)
+func NewAdapter(cfg *goaws.Config, poolID, clientID string, options ...func(*Adapter)) *Adapter {
+	return NewAdapterWithProvider(cognitoidentityprovider.New(cfg.Provider), poolID, clientID, options...)
+}
 
-	return &Adapter{
+// NewAdapterWithProvider creates adapter which sends requests to the given provider instead of Cognito, e.g. to
+// cognitotest.Fake. The provider has to implement the subset of cognitoidentityprovideriface.CognitoIdentityProviderAPI
+// used by Adapter.
+func NewAdapterWithProvider(provider provider, poolID, clientID string, options ...func(*Adapter)) *Adapter {
+	adapter := &Adapter{
 		poolID:   poolID,
 		clientID: clientID,
 		provider: provider,
 	}
+	for _, opt := range options {
+		opt(adapter)
+	}
+	return adapter
 }
 
 func (ca *Adapter) ChangePassword(username, oldPassword, newPassword string) error {
@@ -89,17 +126,23 @@ func (ca *Adapter) ChangePassword(username, oldPassword, newPassword string) err
 		ClientId:   &ca.clientID,
 		UserPoolId: &ca.poolID,
 	}
+	if err := ca.addSecretHash(input.AuthParameters, username); err != nil {
+		return wrapErrWithCode(err, "error in cognito.Adapter while signing in before changing password", ErrCodeSignIn)
+	}
 
 	output, err := ca.provider.AdminInitiateAuth(input)
 	if err != nil {
 		return wrapErrWithCode(err, "error in cognito.Adapter while sending AdminInitiateAuthRequest", ErrCodeSignIn)
 	}
 
-	switch *output.ChallengeName {
-	case cognitoidentityprovider.ChallengeNameTypeNewPasswordRequired:
-		if _, err := ca.respondToAuthChallenge(username, newPassword, output.Session); err != nil {
+	switch challenge := aws.StringValue(output.ChallengeName); {
+	case challenge == cognitoidentityprovider.ChallengeNameTypeNewPasswordRequired:
+		if _, err := ca.RespondToChallenge(username, output.Session, NewPasswordResponse{NewPassword: newPassword}); err != nil {
 			return wrapErrWithCode(err, "error in cognito.Adapter while responding to auth challenge", ErrCodeRespondToAuthChallenge)
 		}
+	case output.AuthenticationResult == nil:
+		return wrapErrWithCode(errors.Errorf("challenge %s has to be answered with RespondToChallenge", challenge),
+			"error in cognito.Adapter while signing in before changing password", ErrCodeChallengeRequired)
 	default:
 		if _, err := ca.changePassword(oldPassword, newPassword, output.AuthenticationResult.AccessToken); err != nil {
 			return wrapErrWithCode(err, "error in cognito.Adapter while changing password", ErrCodeChangePasswordRequest)
@@ -159,7 +202,8 @@ func (ca *Adapter) CreateUser(username, password string, attributesMap map[strin
 	}, nil
 }
 
-func (ca *Adapter) SignIn(username, password string) (*SignInResult, error) {
+// SignIn authenticates the user with password, options such as SignInDeviceKey may modify the request.
+func (ca *Adapter) SignIn(username, password string, options ...func(*cognitoidentityprovider.AdminInitiateAuthInput)) (*SignInResult, error) {
 
 	authFlow := cognitoidentityprovider.AuthFlowTypeAdminNoSrpAuth
 
@@ -172,6 +216,12 @@ func (ca *Adapter) SignIn(username, password string) (*SignInResult, error) {
 		ClientId:   &ca.clientID,
 		UserPoolId: &ca.poolID,
 	}
+	for _, opt := range options {
+		opt(input)
+	}
+	if err := ca.addSecretHash(input.AuthParameters, username); err != nil {
+		return nil, err
+	}
 
 	output, err := ca.provider.AdminInitiateAuth(input)
 	if err != nil {
@@ -179,16 +229,10 @@ func (ca *Adapter) SignIn(username, password string) (*SignInResult, error) {
 	}
 
 	return &SignInResult{
-		AuthenticationResult: &AuthenticationResult{
-			AccessToken:  output.AuthenticationResult.AccessToken,
-			ExpiresIn:    output.AuthenticationResult.ExpiresIn,
-			IDToken:      output.AuthenticationResult.IdToken,
-			RefreshToken: output.AuthenticationResult.RefreshToken,
-			TokenType:    output.AuthenticationResult.TokenType,
-		},
-		ChallengeName:       output.ChallengeName,
-		ChallengeParameters: output.ChallengeParameters,
-		Session:             output.Session,
+		AuthenticationResult: toAuthenticationResult(output.AuthenticationResult),
+		ChallengeName:        output.ChallengeName,
+		ChallengeParameters:  output.ChallengeParameters,
+		Session:              output.Session,
 	}, nil
 }
 
@@ -222,43 +266,26 @@ func (ca *Adapter) ResetUserPassword(username string) error {
 
 func (ca *Adapter) ConfirmForgotPassword(username, newPassword, confirmationCode string) error {
 
+	secretHash, err := ca.secretHash(username)
+	if err != nil {
+		return err
+	}
+
 	input := &cognitoidentityprovider.ConfirmForgotPasswordInput{
 		ClientId:         &ca.clientID,
 		ConfirmationCode: &confirmationCode,
 		Password:         &newPassword,
+		SecretHash:       secretHash,
 		Username:         &username,
 	}
 
-	_, err := ca.provider.ConfirmForgotPassword(input)
+	_, err = ca.provider.ConfirmForgotPassword(input)
 	if err != nil {
 		return wrapErr(err, "error in cognito.Adapter while sending ConfirmForgotPasswordRequest")
 	}
 	return nil
 }
 
-func (ca *Adapter) respondToAuthChallenge(username, password string,
-	session *string) (*cognitoidentityprovider.AdminRespondToAuthChallengeOutput, error) {
-
-	challengeName := cognitoidentityprovider.ChallengeNameTypeNewPasswordRequired
-
-	input := &cognitoidentityprovider.AdminRespondToAuthChallengeInput{
-		ChallengeName: &challengeName,
-		ChallengeResponses: map[string]*string{
-			"USERNAME":     &username,
-			"NEW_PASSWORD": &password,
-		},
-		ClientId:   &ca.clientID,
-		UserPoolId: &ca.poolID,
-		Session:    session,
-	}
-
-	output, err := ca.provider.AdminRespondToAuthChallenge(input)
-	if err != nil {
-		return nil, wrapErr(err, "error in cognito.Adapter while sending AdminRespondToAuthChallengeRequest")
-	}
-	return output, nil
-}
-
 func (ca *Adapter) changePassword(oldPassword, newPassword string, token *string) (*cognitoidentityprovider.ChangePasswordOutput, error) {
 
 	input := &cognitoidentityprovider.ChangePasswordInput{
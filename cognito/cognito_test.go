@@ -3,6 +3,8 @@ package cognito
 import (
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	cip "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
 
 	"github.com/pkg/errors"
@@ -84,6 +86,25 @@ func TestAdapter_ChangePassword(t *testing.T) {
 	}
 }
 
+func TestAdapter_ChangePassword_challengeRequired(t *testing.T) {
+
+	// given
+	challengeName := cip.ChallengeNameTypeSoftwareTokenMfa
+	provider := &providerMock{
+		authOutput: &cip.AdminInitiateAuthOutput{
+			ChallengeName: &challengeName,
+			Session:       &session,
+		},
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	err := adapter.ChangePassword(username, oldPassword, newPassword)
+
+	// then
+	assert.True(t, toAwsgoError(t, err).ChallengeRequired())
+}
+
 func TestAdapter_GetUser_ok(t *testing.T) {
 
 	// given
@@ -192,6 +213,48 @@ func TestAdapter_SignIn_ok(t *testing.T) {
 	assert.NotNil(t, result)
 }
 
+func TestAdapter_SignIn_deviceKey(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		authOutput: &cip.AdminInitiateAuthOutput{
+			AuthenticationResult: &cip.AuthenticationResultType{},
+		},
+	}
+
+	adapter := NewTestAdapter(provider)
+
+	// when
+	_, err := adapter.SignIn(username, newPassword, SignInDeviceKey("device-key"))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "device-key", aws.StringValue(provider.authInput.AuthParameters["DEVICE_KEY"]))
+}
+
+func TestAdapter_SignIn_challenge(t *testing.T) {
+
+	// given
+	challengeName := cip.ChallengeNameTypeSmsMfa
+	provider := &providerMock{
+		authOutput: &cip.AdminInitiateAuthOutput{
+			ChallengeName: &challengeName,
+			Session:       &session,
+		},
+	}
+
+	adapter := NewTestAdapter(provider)
+
+	// when
+	result, err := adapter.SignIn(username, newPassword)
+
+	// then
+	assert.NoError(t, err)
+	assert.Nil(t, result.AuthenticationResult)
+	assert.Equal(t, challengeName, result.Challenge().Name)
+	assert.Equal(t, &session, result.Challenge().Session)
+}
+
 func TestAdapter_SignIn_error(t *testing.T) {
 
 	// given
@@ -313,15 +376,23 @@ func toAwsgoError(t *testing.T, err error) Error {
 	return awsgoError
 }
 
+func awsError(code string) error {
+	return awserr.New(code, code, nil)
+}
+
 type providerMock struct {
 	getUserOutput                *cip.GetUserOutput
 	getUserErr                   error
 	createUserOutput             *cip.AdminCreateUserOutput
 	createUserErr                error
+	authInput                    *cip.AdminInitiateAuthInput
+	authCalls                    int
 	authOutput                   *cip.AdminInitiateAuthOutput
 	authErr                      error
+	forgetPassInput              *cip.ConfirmForgotPasswordInput
 	forgetPassOutput             *cip.ConfirmForgotPasswordOutput
 	forgetPassErr                error
+	respondToAuthChallengeInput  *cip.AdminRespondToAuthChallengeInput
 	respondToAuthChallengeOutput *cip.AdminRespondToAuthChallengeOutput
 	respondToAuthChallengeErr    error
 	changePasswordOutput         *cip.ChangePasswordOutput
@@ -330,6 +401,58 @@ type providerMock struct {
 	signOutErr                   error
 	resetPassOutput              *cip.AdminResetUserPasswordOutput
 	resetPassErr                 error
+	globalSignOutOutput          *cip.GlobalSignOutOutput
+	globalSignOutErr             error
+	signUpInput                  *cip.SignUpInput
+	signUpOutput                 *cip.SignUpOutput
+	signUpErr                    error
+	confirmSignUpInput           *cip.ConfirmSignUpInput
+	confirmSignUpErr             error
+	resendCodeOutput             *cip.ResendConfirmationCodeOutput
+	resendCodeErr                error
+	forgotPassInput              *cip.ForgotPasswordInput
+	forgotPassOutput             *cip.ForgotPasswordOutput
+	forgotPassErr                error
+	listUsersInputs              []*cip.ListUsersInput
+	listUsersOutputs             []*cip.ListUsersOutput
+	listUsersErr                 error
+	adminGetUserOutput           *cip.AdminGetUserOutput
+	adminGetUserErr              error
+	updateAttributesInput        *cip.AdminUpdateUserAttributesInput
+	updateAttributesErr          error
+	deleteAttributesInput        *cip.AdminDeleteUserAttributesInput
+	deleteAttributesErr          error
+	disableUserErr               error
+	enableUserErr                error
+	deleteUserErr                error
+	setPasswordInput             *cip.AdminSetUserPasswordInput
+	setPasswordErr               error
+	createGroupInput             *cip.CreateGroupInput
+	createGroupErr               error
+	getGroupOutput               *cip.GetGroupOutput
+	getGroupErr                  error
+	updateGroupInput             *cip.UpdateGroupInput
+	updateGroupErr               error
+	deleteGroupErr               error
+	listGroupsInputs             []*cip.ListGroupsInput
+	listGroupsOutputs            []*cip.ListGroupsOutput
+	listGroupsErr                error
+	addUserToGroupErr            error
+	removeUserFromGroupErr       error
+	groupsForUserOutput          *cip.AdminListGroupsForUserOutput
+	groupsForUserErr             error
+	usersInGroupOutput           *cip.ListUsersInGroupOutput
+	usersInGroupErr              error
+	associateTokenInput          *cip.AssociateSoftwareTokenInput
+	associateTokenOutput         *cip.AssociateSoftwareTokenOutput
+	associateTokenErr            error
+	verifyTokenInput             *cip.VerifySoftwareTokenInput
+	verifyTokenOutput            *cip.VerifySoftwareTokenOutput
+	verifyTokenErr               error
+	setMFAPreferenceInput        *cip.SetUserMFAPreferenceInput
+	setMFAPreferenceErr          error
+	adminSetMFAPreferenceInput   *cip.AdminSetUserMFAPreferenceInput
+	adminSetMFAPreferenceErr     error
 }
 
 func (pm *providerMock) GetUser(*cip.GetUserInput) (*cip.GetUserOutput, error) {
@@ -340,15 +463,19 @@ func (pm *providerMock) AdminCreateUser(*cip.AdminCreateUserInput) (*cip.AdminCr
 	return pm.createUserOutput, pm.createUserErr
 }
 
-func (pm *providerMock) AdminInitiateAuth(*cip.AdminInitiateAuthInput) (*cip.AdminInitiateAuthOutput, error) {
+func (pm *providerMock) AdminInitiateAuth(input *cip.AdminInitiateAuthInput) (*cip.AdminInitiateAuthOutput, error) {
+	pm.authInput = input
+	pm.authCalls++
 	return pm.authOutput, pm.authErr
 }
 
-func (pm *providerMock) ConfirmForgotPassword(*cip.ConfirmForgotPasswordInput) (*cip.ConfirmForgotPasswordOutput, error) {
+func (pm *providerMock) ConfirmForgotPassword(input *cip.ConfirmForgotPasswordInput) (*cip.ConfirmForgotPasswordOutput, error) {
+	pm.forgetPassInput = input
 	return pm.forgetPassOutput, pm.forgetPassErr
 }
 
-func (pm *providerMock) AdminRespondToAuthChallenge(*cip.AdminRespondToAuthChallengeInput) (*cip.AdminRespondToAuthChallengeOutput, error) {
+func (pm *providerMock) AdminRespondToAuthChallenge(input *cip.AdminRespondToAuthChallengeInput) (*cip.AdminRespondToAuthChallengeOutput, error) {
+	pm.respondToAuthChallengeInput = input
 	return pm.respondToAuthChallengeOutput, pm.respondToAuthChallengeErr
 }
 
@@ -364,6 +491,136 @@ func (pm *providerMock) AdminResetUserPassword(input *cip.AdminResetUserPassword
 	return pm.resetPassOutput, pm.resetPassErr
 }
 
+func (pm *providerMock) GlobalSignOut(*cip.GlobalSignOutInput) (*cip.GlobalSignOutOutput, error) {
+	return pm.globalSignOutOutput, pm.globalSignOutErr
+}
+
+func (pm *providerMock) SignUp(input *cip.SignUpInput) (*cip.SignUpOutput, error) {
+	pm.signUpInput = input
+	return pm.signUpOutput, pm.signUpErr
+}
+
+func (pm *providerMock) ConfirmSignUp(input *cip.ConfirmSignUpInput) (*cip.ConfirmSignUpOutput, error) {
+	pm.confirmSignUpInput = input
+	return &cip.ConfirmSignUpOutput{}, pm.confirmSignUpErr
+}
+
+func (pm *providerMock) ResendConfirmationCode(*cip.ResendConfirmationCodeInput) (*cip.ResendConfirmationCodeOutput, error) {
+	return pm.resendCodeOutput, pm.resendCodeErr
+}
+
+func (pm *providerMock) ForgotPassword(input *cip.ForgotPasswordInput) (*cip.ForgotPasswordOutput, error) {
+	pm.forgotPassInput = input
+	return pm.forgotPassOutput, pm.forgotPassErr
+}
+
+func (pm *providerMock) ListUsers(input *cip.ListUsersInput) (*cip.ListUsersOutput, error) {
+	copied := *input
+	pm.listUsersInputs = append(pm.listUsersInputs, &copied)
+	if pm.listUsersErr != nil {
+		return nil, pm.listUsersErr
+	}
+	output := pm.listUsersOutputs[0]
+	pm.listUsersOutputs = pm.listUsersOutputs[1:]
+	return output, nil
+}
+
+func (pm *providerMock) AdminGetUser(*cip.AdminGetUserInput) (*cip.AdminGetUserOutput, error) {
+	return pm.adminGetUserOutput, pm.adminGetUserErr
+}
+
+func (pm *providerMock) AdminUpdateUserAttributes(input *cip.AdminUpdateUserAttributesInput) (*cip.AdminUpdateUserAttributesOutput, error) {
+	pm.updateAttributesInput = input
+	return &cip.AdminUpdateUserAttributesOutput{}, pm.updateAttributesErr
+}
+
+func (pm *providerMock) AdminDeleteUserAttributes(input *cip.AdminDeleteUserAttributesInput) (*cip.AdminDeleteUserAttributesOutput, error) {
+	pm.deleteAttributesInput = input
+	return &cip.AdminDeleteUserAttributesOutput{}, pm.deleteAttributesErr
+}
+
+func (pm *providerMock) AdminDisableUser(*cip.AdminDisableUserInput) (*cip.AdminDisableUserOutput, error) {
+	return &cip.AdminDisableUserOutput{}, pm.disableUserErr
+}
+
+func (pm *providerMock) AdminEnableUser(*cip.AdminEnableUserInput) (*cip.AdminEnableUserOutput, error) {
+	return &cip.AdminEnableUserOutput{}, pm.enableUserErr
+}
+
+func (pm *providerMock) AdminDeleteUser(*cip.AdminDeleteUserInput) (*cip.AdminDeleteUserOutput, error) {
+	return &cip.AdminDeleteUserOutput{}, pm.deleteUserErr
+}
+
+func (pm *providerMock) AdminSetUserPassword(input *cip.AdminSetUserPasswordInput) (*cip.AdminSetUserPasswordOutput, error) {
+	pm.setPasswordInput = input
+	return &cip.AdminSetUserPasswordOutput{}, pm.setPasswordErr
+}
+
+func (pm *providerMock) CreateGroup(input *cip.CreateGroupInput) (*cip.CreateGroupOutput, error) {
+	pm.createGroupInput = input
+	return &cip.CreateGroupOutput{Group: &cip.GroupType{GroupName: input.GroupName, Precedence: input.Precedence}}, pm.createGroupErr
+}
+
+func (pm *providerMock) GetGroup(*cip.GetGroupInput) (*cip.GetGroupOutput, error) {
+	return pm.getGroupOutput, pm.getGroupErr
+}
+
+func (pm *providerMock) UpdateGroup(input *cip.UpdateGroupInput) (*cip.UpdateGroupOutput, error) {
+	pm.updateGroupInput = input
+	return &cip.UpdateGroupOutput{Group: &cip.GroupType{GroupName: input.GroupName}}, pm.updateGroupErr
+}
+
+func (pm *providerMock) DeleteGroup(*cip.DeleteGroupInput) (*cip.DeleteGroupOutput, error) {
+	return &cip.DeleteGroupOutput{}, pm.deleteGroupErr
+}
+
+func (pm *providerMock) ListGroups(input *cip.ListGroupsInput) (*cip.ListGroupsOutput, error) {
+	copied := *input
+	pm.listGroupsInputs = append(pm.listGroupsInputs, &copied)
+	if pm.listGroupsErr != nil {
+		return nil, pm.listGroupsErr
+	}
+	output := pm.listGroupsOutputs[0]
+	pm.listGroupsOutputs = pm.listGroupsOutputs[1:]
+	return output, nil
+}
+
+func (pm *providerMock) AdminAddUserToGroup(*cip.AdminAddUserToGroupInput) (*cip.AdminAddUserToGroupOutput, error) {
+	return &cip.AdminAddUserToGroupOutput{}, pm.addUserToGroupErr
+}
+
+func (pm *providerMock) AdminRemoveUserFromGroup(*cip.AdminRemoveUserFromGroupInput) (*cip.AdminRemoveUserFromGroupOutput, error) {
+	return &cip.AdminRemoveUserFromGroupOutput{}, pm.removeUserFromGroupErr
+}
+
+func (pm *providerMock) AdminListGroupsForUser(*cip.AdminListGroupsForUserInput) (*cip.AdminListGroupsForUserOutput, error) {
+	return pm.groupsForUserOutput, pm.groupsForUserErr
+}
+
+func (pm *providerMock) ListUsersInGroup(*cip.ListUsersInGroupInput) (*cip.ListUsersInGroupOutput, error) {
+	return pm.usersInGroupOutput, pm.usersInGroupErr
+}
+
+func (pm *providerMock) AssociateSoftwareToken(input *cip.AssociateSoftwareTokenInput) (*cip.AssociateSoftwareTokenOutput, error) {
+	pm.associateTokenInput = input
+	return pm.associateTokenOutput, pm.associateTokenErr
+}
+
+func (pm *providerMock) VerifySoftwareToken(input *cip.VerifySoftwareTokenInput) (*cip.VerifySoftwareTokenOutput, error) {
+	pm.verifyTokenInput = input
+	return pm.verifyTokenOutput, pm.verifyTokenErr
+}
+
+func (pm *providerMock) SetUserMFAPreference(input *cip.SetUserMFAPreferenceInput) (*cip.SetUserMFAPreferenceOutput, error) {
+	pm.setMFAPreferenceInput = input
+	return &cip.SetUserMFAPreferenceOutput{}, pm.setMFAPreferenceErr
+}
+
+func (pm *providerMock) AdminSetUserMFAPreference(input *cip.AdminSetUserMFAPreferenceInput) (*cip.AdminSetUserMFAPreferenceOutput, error) {
+	pm.adminSetMFAPreferenceInput = input
+	return &cip.AdminSetUserMFAPreferenceOutput{}, pm.adminSetMFAPreferenceErr
+}
+
 func TestProviderMockImplementsProvider(t *testing.T) {
 	var _ provider = &providerMock{}
 }
@@ -0,0 +1,1172 @@
+// Package cognitotest provides an in-memory user pool for testing code which uses cognito.Adapter.
+package cognitotest
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Ryanair/goaws/cognito"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	cip "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+)
+
+const (
+	fakeTokenExpiresIn   = 3600
+	fakeMinPasswordLen   = 8
+	fakeDefaultPageLimit = 60
+	totpPeriod           = 30
+	userAttributesPrefix = "userAttributes."
+)
+
+// Fake is an in-memory user pool backing cognito.Adapter. It keeps users, passwords, statuses, groups, tokens and
+// codes, and returns the same error codes as Cognito so Error predicates behave the same way as with the real service.
+// Tokens are opaque strings, not JWTs. It is safe for concurrent use.
+type Fake struct {
+	mu            sync.Mutex
+	poolID        string
+	users         map[string]*fakeUser
+	groups        map[string]*cip.GroupType
+	accessTokens  map[string]string
+	refreshTokens map[string]string
+	sessions      map[string]*fakeSession
+	codes         map[string]string
+	now           func() time.Time
+}
+
+type fakeUser struct {
+	username      string
+	password      string
+	status        string
+	enabled       bool
+	attributes    map[string]string
+	created       time.Time
+	modified      time.Time
+	code          string
+	groups        map[string]bool
+	totpSecret    string
+	totpVerified  bool
+	mfaEnabled    map[string]bool
+	preferredMFA  string
+	pendingSecret string
+}
+
+type fakeSession struct {
+	username  string
+	challenge string
+	code      string
+}
+
+func NewFake(poolID string) *Fake {
+	return &Fake{
+		poolID:        poolID,
+		users:         make(map[string]*fakeUser),
+		groups:        make(map[string]*cip.GroupType),
+		accessTokens:  make(map[string]string),
+		refreshTokens: make(map[string]string),
+		sessions:      make(map[string]*fakeSession),
+		codes:         make(map[string]string),
+		now:           time.Now,
+	}
+}
+
+// Adapter returns cognito.Adapter backed by the pool.
+func (p *Fake) Adapter(clientID string, options ...func(*cognito.Adapter)) *cognito.Adapter {
+	return cognito.NewAdapterWithProvider(p, p.poolID, clientID, options...)
+}
+
+// Code returns the last code sent to the user, i.e. sign up confirmation, forgot password or SMS MFA code.
+func (p *Fake) Code(username string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	code, ok := p.codes[username]
+	return code, ok
+}
+
+// SoftwareTokenCode returns the current TOTP code of the user's associated software token.
+func (p *Fake) SoftwareTokenCode(username string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	u, ok := p.users[username]
+	if !ok {
+		return "", false
+	}
+	secret := u.totpSecret
+	if u.pendingSecret != "" {
+		secret = u.pendingSecret
+	}
+	if secret == "" {
+		return "", false
+	}
+	return totp(secret, p.now()), true
+}
+
+func (p *Fake) SignUp(in *cip.SignUpInput) (*cip.SignUpOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	username := aws.StringValue(in.Username)
+	if _, ok := p.users[username]; ok {
+		return nil, awserr.New(cip.ErrCodeUsernameExistsException, "User already exists", nil)
+	}
+	if err := checkPassword(aws.StringValue(in.Password)); err != nil {
+		return nil, err
+	}
+
+	u := p.newUser(username, in.UserAttributes)
+	u.password = aws.StringValue(in.Password)
+	u.status = cip.UserStatusTypeUnconfirmed
+	u.code = p.sendCode(u.username)
+	p.users[username] = u
+
+	return &cip.SignUpOutput{
+		CodeDeliveryDetails: u.codeDelivery(),
+		UserConfirmed:       aws.Bool(false),
+		UserSub:             aws.String(u.attributes["sub"]),
+	}, nil
+}
+
+func (p *Fake) ConfirmSignUp(in *cip.ConfirmSignUpInput) (*cip.ConfirmSignUpOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	u, err := p.user(in.Username)
+	if err != nil {
+		return nil, err
+	}
+	if u.status != cip.UserStatusTypeUnconfirmed {
+		return nil, awserr.New(cip.ErrCodeNotAuthorizedException, "User cannot be confirmed. Current status is "+u.status, nil)
+	}
+	if u.code != aws.StringValue(in.ConfirmationCode) {
+		return nil, codeMismatch()
+	}
+
+	u.status = cip.UserStatusTypeConfirmed
+	u.code = ""
+	u.modified = p.now()
+	return &cip.ConfirmSignUpOutput{}, nil
+}
+
+func (p *Fake) ResendConfirmationCode(in *cip.ResendConfirmationCodeInput) (*cip.ResendConfirmationCodeOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	u, err := p.user(in.Username)
+	if err != nil {
+		return nil, err
+	}
+	if u.status != cip.UserStatusTypeUnconfirmed {
+		return nil, awserr.New(cip.ErrCodeInvalidParameterException, "User is already confirmed.", nil)
+	}
+
+	u.code = p.sendCode(u.username)
+	return &cip.ResendConfirmationCodeOutput{CodeDeliveryDetails: u.codeDelivery()}, nil
+}
+
+func (p *Fake) ForgotPassword(in *cip.ForgotPasswordInput) (*cip.ForgotPasswordOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	u, err := p.user(in.Username)
+	if err != nil {
+		return nil, err
+	}
+	if !u.enabled {
+		return nil, userDisabled()
+	}
+
+	u.code = p.sendCode(u.username)
+	return &cip.ForgotPasswordOutput{CodeDeliveryDetails: u.codeDelivery()}, nil
+}
+
+func (p *Fake) ConfirmForgotPassword(in *cip.ConfirmForgotPasswordInput) (*cip.ConfirmForgotPasswordOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	u, err := p.user(in.Username)
+	if err != nil {
+		return nil, err
+	}
+	if u.code == "" || u.code != aws.StringValue(in.ConfirmationCode) {
+		return nil, codeMismatch()
+	}
+	if err := checkPassword(aws.StringValue(in.Password)); err != nil {
+		return nil, err
+	}
+
+	u.password = aws.StringValue(in.Password)
+	u.status = cip.UserStatusTypeConfirmed
+	u.code = ""
+	u.modified = p.now()
+	return &cip.ConfirmForgotPasswordOutput{}, nil
+}
+
+func (p *Fake) AdminCreateUser(in *cip.AdminCreateUserInput) (*cip.AdminCreateUserOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	username := aws.StringValue(in.Username)
+	if _, ok := p.users[username]; ok {
+		return nil, awserr.New(cip.ErrCodeUsernameExistsException, "User account already exists", nil)
+	}
+	password := aws.StringValue(in.TemporaryPassword)
+	if password == "" {
+		password = randomID()
+	} else if err := checkPassword(password); err != nil {
+		return nil, err
+	}
+
+	u := p.newUser(username, in.UserAttributes)
+	u.password = password
+	u.status = cip.UserStatusTypeForceChangePassword
+	p.users[username] = u
+
+	return &cip.AdminCreateUserOutput{User: u.userType()}, nil
+}
+
+func (p *Fake) AdminGetUser(in *cip.AdminGetUserInput) (*cip.AdminGetUserOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	u, err := p.user(in.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cip.AdminGetUserOutput{
+		Enabled:              aws.Bool(u.enabled),
+		PreferredMfaSetting:  u.preferred(),
+		UserAttributes:       toAttributes(u.attributes),
+		UserCreateDate:       aws.Time(u.created),
+		UserLastModifiedDate: aws.Time(u.modified),
+		UserMFASettingList:   u.mfaSettings(),
+		UserStatus:           aws.String(u.status),
+		Username:             aws.String(u.username),
+	}, nil
+}
+
+func (p *Fake) GetUser(in *cip.GetUserInput) (*cip.GetUserOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	u, err := p.userByToken(in.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cip.GetUserOutput{
+		PreferredMfaSetting: u.preferred(),
+		UserAttributes:      toAttributes(u.attributes),
+		UserMFASettingList:  u.mfaSettings(),
+		Username:            aws.String(u.username),
+	}, nil
+}
+
+func (p *Fake) ListUsers(in *cip.ListUsersInput) (*cip.ListUsersOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	match, err := parseFilter(aws.StringValue(in.Filter))
+	if err != nil {
+		return nil, err
+	}
+
+	var users []*cip.UserType
+	for _, name := range p.usernames() {
+		u := p.users[name]
+		if !match(u) {
+			continue
+		}
+		user := u.userType()
+		if len(in.AttributesToGet) > 0 {
+			user.Attributes = nil
+			for _, attr := range in.AttributesToGet {
+				if v, ok := u.attributes[*attr]; ok {
+					user.Attributes = append(user.Attributes, attribute(*attr, v))
+				}
+			}
+		}
+		users = append(users, user)
+	}
+
+	page, next, err := paginate(len(users), in.Limit, in.PaginationToken)
+	if err != nil {
+		return nil, err
+	}
+	return &cip.ListUsersOutput{Users: users[page[0]:page[1]], PaginationToken: next}, nil
+}
+
+func (p *Fake) AdminUpdateUserAttributes(in *cip.AdminUpdateUserAttributesInput) (*cip.AdminUpdateUserAttributesOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	u, err := p.user(in.Username)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range fromAttributes(in.UserAttributes) {
+		if name == "sub" {
+			return nil, awserr.New(cip.ErrCodeInvalidParameterException, "Cannot modify an immutable attribute: sub", nil)
+		}
+		u.attributes[name] = value
+	}
+	u.modified = p.now()
+	return &cip.AdminUpdateUserAttributesOutput{}, nil
+}
+
+func (p *Fake) AdminDeleteUserAttributes(in *cip.AdminDeleteUserAttributesInput) (*cip.AdminDeleteUserAttributesOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	u, err := p.user(in.Username)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range in.UserAttributeNames {
+		if *name == "sub" {
+			return nil, awserr.New(cip.ErrCodeInvalidParameterException, "Cannot modify an immutable attribute: sub", nil)
+		}
+		delete(u.attributes, *name)
+	}
+	u.modified = p.now()
+	return &cip.AdminDeleteUserAttributesOutput{}, nil
+}
+
+func (p *Fake) AdminDisableUser(in *cip.AdminDisableUserInput) (*cip.AdminDisableUserOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	u, err := p.user(in.Username)
+	if err != nil {
+		return nil, err
+	}
+	u.enabled = false
+	u.modified = p.now()
+	p.revoke(u.username)
+	return &cip.AdminDisableUserOutput{}, nil
+}
+
+func (p *Fake) AdminEnableUser(in *cip.AdminEnableUserInput) (*cip.AdminEnableUserOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	u, err := p.user(in.Username)
+	if err != nil {
+		return nil, err
+	}
+	u.enabled = true
+	u.modified = p.now()
+	return &cip.AdminEnableUserOutput{}, nil
+}
+
+func (p *Fake) AdminDeleteUser(in *cip.AdminDeleteUserInput) (*cip.AdminDeleteUserOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	u, err := p.user(in.Username)
+	if err != nil {
+		return nil, err
+	}
+	p.revoke(u.username)
+	delete(p.users, u.username)
+	return &cip.AdminDeleteUserOutput{}, nil
+}
+
+func (p *Fake) AdminSetUserPassword(in *cip.AdminSetUserPasswordInput) (*cip.AdminSetUserPasswordOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	u, err := p.user(in.Username)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPassword(aws.StringValue(in.Password)); err != nil {
+		return nil, err
+	}
+
+	u.password = aws.StringValue(in.Password)
+	u.status = cip.UserStatusTypeForceChangePassword
+	if aws.BoolValue(in.Permanent) {
+		u.status = cip.UserStatusTypeConfirmed
+	}
+	u.modified = p.now()
+	return &cip.AdminSetUserPasswordOutput{}, nil
+}
+
+func (p *Fake) AdminResetUserPassword(in *cip.AdminResetUserPasswordInput) (*cip.AdminResetUserPasswordOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	u, err := p.user(in.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	u.status = cip.UserStatusTypeResetRequired
+	u.code = p.sendCode(u.username)
+	u.modified = p.now()
+	p.revoke(u.username)
+	return &cip.AdminResetUserPasswordOutput{}, nil
+}
+
+// AdminInitiateAuth supports ADMIN_NO_SRP_AUTH, ADMIN_USER_PASSWORD_AUTH and REFRESH_TOKEN_AUTH flows.
+func (p *Fake) AdminInitiateAuth(in *cip.AdminInitiateAuthInput) (*cip.AdminInitiateAuthOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	params := in.AuthParameters
+	switch aws.StringValue(in.AuthFlow) {
+	case cip.AuthFlowTypeAdminNoSrpAuth, cip.AuthFlowTypeAdminUserPasswordAuth:
+	case cip.AuthFlowTypeRefreshTokenAuth, cip.AuthFlowTypeRefreshToken:
+		username, ok := p.refreshTokens[parameter(params, "REFRESH_TOKEN")]
+		if !ok {
+			return nil, awserr.New(cip.ErrCodeNotAuthorizedException, "Invalid Refresh Token", nil)
+		}
+		access, id := p.issueAccessTokens(username)
+		return &cip.AdminInitiateAuthOutput{AuthenticationResult: &cip.AuthenticationResultType{
+			AccessToken: &access,
+			ExpiresIn:   aws.Int64(fakeTokenExpiresIn),
+			IdToken:     &id,
+			TokenType:   aws.String("Bearer"),
+		}}, nil
+	default:
+		return nil, awserr.New(cip.ErrCodeInvalidParameterException, "Unsupported auth flow "+aws.StringValue(in.AuthFlow), nil)
+	}
+
+	u, ok := p.users[parameter(params, "USERNAME")]
+	if !ok {
+		return nil, awserr.New(cip.ErrCodeUserNotFoundException, "User does not exist.", nil)
+	}
+	if u.password != parameter(params, "PASSWORD") {
+		return nil, notAuthorized()
+	}
+	if !u.enabled {
+		return nil, userDisabled()
+	}
+
+	switch u.status {
+	case cip.UserStatusTypeUnconfirmed:
+		return nil, awserr.New(cip.ErrCodeUserNotConfirmedException, "User is not confirmed.", nil)
+	case cip.UserStatusTypeResetRequired:
+		return nil, awserr.New(cip.ErrCodePasswordResetRequiredException, "Password reset required for the user", nil)
+	case cip.UserStatusTypeForceChangePassword:
+		return p.challenge(u, cip.ChallengeNameTypeNewPasswordRequired).adminInitiateAuthOutput(), nil
+	}
+	return p.authenticated(u).adminInitiateAuthOutput(), nil
+}
+
+func (p *Fake) AdminRespondToAuthChallenge(in *cip.AdminRespondToAuthChallengeInput) (*cip.AdminRespondToAuthChallengeOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	session, ok := p.sessions[aws.StringValue(in.Session)]
+	responses := in.ChallengeResponses
+	if !ok || session.challenge != aws.StringValue(in.ChallengeName) || session.username != parameter(responses, "USERNAME") {
+		return nil, awserr.New(cip.ErrCodeNotAuthorizedException, "Invalid session for the user.", nil)
+	}
+	u, ok := p.users[session.username]
+	if !ok {
+		return nil, awserr.New(cip.ErrCodeUserNotFoundException, "User does not exist.", nil)
+	}
+
+	switch session.challenge {
+	case cip.ChallengeNameTypeNewPasswordRequired:
+		password := parameter(responses, "NEW_PASSWORD")
+		if err := checkPassword(password); err != nil {
+			return nil, err
+		}
+		for name, value := range responses {
+			if strings.HasPrefix(name, userAttributesPrefix) {
+				u.attributes[strings.TrimPrefix(name, userAttributesPrefix)] = aws.StringValue(value)
+			}
+		}
+		u.password = password
+		u.status = cip.UserStatusTypeConfirmed
+		u.modified = p.now()
+	case cip.ChallengeNameTypeSmsMfa:
+		if parameter(responses, "SMS_MFA_CODE") != session.code {
+			return nil, codeMismatch()
+		}
+		delete(p.sessions, aws.StringValue(in.Session))
+		return p.issueTokens(u).adminRespondToAuthChallengeOutput(), nil
+	case cip.ChallengeNameTypeSoftwareTokenMfa:
+		if parameter(responses, "SOFTWARE_TOKEN_MFA_CODE") != totp(u.totpSecret, p.now()) {
+			return nil, codeMismatch()
+		}
+		delete(p.sessions, aws.StringValue(in.Session))
+		return p.issueTokens(u).adminRespondToAuthChallengeOutput(), nil
+	case cip.ChallengeNameTypeSelectMfaType:
+		answer := parameter(responses, "ANSWER")
+		if !u.mfaEnabled[answer] {
+			return nil, awserr.New(cip.ErrCodeInvalidParameterException, "Invalid MFA type "+answer, nil)
+		}
+		delete(p.sessions, aws.StringValue(in.Session))
+		return p.challenge(u, answer).adminRespondToAuthChallengeOutput(), nil
+	default:
+		return nil, awserr.New(cip.ErrCodeInvalidParameterException, "Unsupported challenge "+session.challenge, nil)
+	}
+
+	delete(p.sessions, aws.StringValue(in.Session))
+	return p.authenticated(u).adminRespondToAuthChallengeOutput(), nil
+}
+
+func (p *Fake) ChangePassword(in *cip.ChangePasswordInput) (*cip.ChangePasswordOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	u, err := p.userByToken(in.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	if u.password != aws.StringValue(in.PreviousPassword) {
+		return nil, notAuthorized()
+	}
+	if err := checkPassword(aws.StringValue(in.ProposedPassword)); err != nil {
+		return nil, err
+	}
+
+	u.password = aws.StringValue(in.ProposedPassword)
+	u.modified = p.now()
+	return &cip.ChangePasswordOutput{}, nil
+}
+
+func (p *Fake) GlobalSignOut(in *cip.GlobalSignOutInput) (*cip.GlobalSignOutOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	u, err := p.userByToken(in.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	p.revoke(u.username)
+	return &cip.GlobalSignOutOutput{}, nil
+}
+
+func (p *Fake) AdminUserGlobalSignOut(in *cip.AdminUserGlobalSignOutInput) (*cip.AdminUserGlobalSignOutOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	u, err := p.user(in.Username)
+	if err != nil {
+		return nil, err
+	}
+	p.revoke(u.username)
+	return &cip.AdminUserGlobalSignOutOutput{}, nil
+}
+
+func (p *Fake) CreateGroup(in *cip.CreateGroupInput) (*cip.CreateGroupOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	name := aws.StringValue(in.GroupName)
+	if _, ok := p.groups[name]; ok {
+		return nil, awserr.New(cip.ErrCodeGroupExistsException, "A group with the name "+name+" already exists.", nil)
+	}
+
+	now := p.now()
+	group := &cip.GroupType{
+		CreationDate:     aws.Time(now),
+		Description:      in.Description,
+		GroupName:        &name,
+		LastModifiedDate: aws.Time(now),
+		Precedence:       in.Precedence,
+		RoleArn:          in.RoleArn,
+		UserPoolId:       &p.poolID,
+	}
+	p.groups[name] = group
+	return &cip.CreateGroupOutput{Group: copyGroup(group)}, nil
+}
+
+func (p *Fake) UpdateGroup(in *cip.UpdateGroupInput) (*cip.UpdateGroupOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	group, err := p.group(in.GroupName)
+	if err != nil {
+		return nil, err
+	}
+	group.Description, group.Precedence, group.RoleArn = in.Description, in.Precedence, in.RoleArn
+	group.LastModifiedDate = aws.Time(p.now())
+	return &cip.UpdateGroupOutput{Group: copyGroup(group)}, nil
+}
+
+func (p *Fake) GetGroup(in *cip.GetGroupInput) (*cip.GetGroupOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	group, err := p.group(in.GroupName)
+	if err != nil {
+		return nil, err
+	}
+	return &cip.GetGroupOutput{Group: copyGroup(group)}, nil
+}
+
+func (p *Fake) DeleteGroup(in *cip.DeleteGroupInput) (*cip.DeleteGroupOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	group, err := p.group(in.GroupName)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range p.users {
+		delete(u.groups, *group.GroupName)
+	}
+	delete(p.groups, *group.GroupName)
+	return &cip.DeleteGroupOutput{}, nil
+}
+
+func (p *Fake) ListGroups(in *cip.ListGroupsInput) (*cip.ListGroupsOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	groups := p.sortedGroups(func(string) bool { return true })
+	page, next, err := paginate(len(groups), in.Limit, in.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &cip.ListGroupsOutput{Groups: groups[page[0]:page[1]], NextToken: next}, nil
+}
+
+func (p *Fake) AdminAddUserToGroup(in *cip.AdminAddUserToGroupInput) (*cip.AdminAddUserToGroupOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	u, err := p.user(in.Username)
+	if err != nil {
+		return nil, err
+	}
+	group, err := p.group(in.GroupName)
+	if err != nil {
+		return nil, err
+	}
+	u.groups[*group.GroupName] = true
+	return &cip.AdminAddUserToGroupOutput{}, nil
+}
+
+func (p *Fake) AdminRemoveUserFromGroup(in *cip.AdminRemoveUserFromGroupInput) (*cip.AdminRemoveUserFromGroupOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	u, err := p.user(in.Username)
+	if err != nil {
+		return nil, err
+	}
+	group, err := p.group(in.GroupName)
+	if err != nil {
+		return nil, err
+	}
+	delete(u.groups, *group.GroupName)
+	return &cip.AdminRemoveUserFromGroupOutput{}, nil
+}
+
+func (p *Fake) AdminListGroupsForUser(in *cip.AdminListGroupsForUserInput) (*cip.AdminListGroupsForUserOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	u, err := p.user(in.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := p.sortedGroups(func(name string) bool { return u.groups[name] })
+	page, next, err := paginate(len(groups), in.Limit, in.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &cip.AdminListGroupsForUserOutput{Groups: groups[page[0]:page[1]], NextToken: next}, nil
+}
+
+func (p *Fake) ListUsersInGroup(in *cip.ListUsersInGroupInput) (*cip.ListUsersInGroupOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	group, err := p.group(in.GroupName)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []*cip.UserType
+	for _, name := range p.usernames() {
+		if u := p.users[name]; u.groups[*group.GroupName] {
+			users = append(users, u.userType())
+		}
+	}
+	page, next, err := paginate(len(users), in.Limit, in.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	return &cip.ListUsersInGroupOutput{Users: users[page[0]:page[1]], NextToken: next}, nil
+}
+
+func (p *Fake) AssociateSoftwareToken(in *cip.AssociateSoftwareTokenInput) (*cip.AssociateSoftwareTokenOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	u, err := p.userByToken(in.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, 20)
+	_, _ = rand.Read(secret)
+	u.pendingSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret)
+	return &cip.AssociateSoftwareTokenOutput{SecretCode: aws.String(u.pendingSecret)}, nil
+}
+
+func (p *Fake) VerifySoftwareToken(in *cip.VerifySoftwareTokenInput) (*cip.VerifySoftwareTokenOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	u, err := p.userByToken(in.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	if u.pendingSecret == "" {
+		return nil, awserr.New(cip.ErrCodeInvalidParameterException, "User has not associated software token.", nil)
+	}
+	if aws.StringValue(in.UserCode) != totp(u.pendingSecret, p.now()) {
+		return nil, awserr.New(cip.ErrCodeEnableSoftwareTokenMFAException, "Code mismatch", nil)
+	}
+
+	u.totpSecret, u.pendingSecret, u.totpVerified = u.pendingSecret, "", true
+	return &cip.VerifySoftwareTokenOutput{Status: aws.String(cip.VerifySoftwareTokenResponseTypeSuccess)}, nil
+}
+
+func (p *Fake) SetUserMFAPreference(in *cip.SetUserMFAPreferenceInput) (*cip.SetUserMFAPreferenceOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	u, err := p.userByToken(in.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.setMFAPreference(in.SMSMfaSettings, in.SoftwareTokenMfaSettings); err != nil {
+		return nil, err
+	}
+	return &cip.SetUserMFAPreferenceOutput{}, nil
+}
+
+func (p *Fake) AdminSetUserMFAPreference(in *cip.AdminSetUserMFAPreferenceInput) (*cip.AdminSetUserMFAPreferenceOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	u, err := p.user(in.Username)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.setMFAPreference(in.SMSMfaSettings, in.SoftwareTokenMfaSettings); err != nil {
+		return nil, err
+	}
+	return &cip.AdminSetUserMFAPreferenceOutput{}, nil
+}
+
+func (p *Fake) newUser(username string, attrs []*cip.AttributeType) *fakeUser {
+	now := p.now()
+	u := &fakeUser{
+		username:   username,
+		enabled:    true,
+		attributes: fromAttributes(attrs),
+		created:    now,
+		modified:   now,
+		groups:     make(map[string]bool),
+		mfaEnabled: make(map[string]bool),
+	}
+	u.attributes["sub"] = randomUUID()
+	return u
+}
+
+func (p *Fake) user(username *string) (*fakeUser, error) {
+	u, ok := p.users[aws.StringValue(username)]
+	if !ok {
+		return nil, awserr.New(cip.ErrCodeUserNotFoundException, "User does not exist.", nil)
+	}
+	return u, nil
+}
+
+func (p *Fake) userByToken(accessToken *string) (*fakeUser, error) {
+	username, ok := p.accessTokens[aws.StringValue(accessToken)]
+	if !ok {
+		return nil, awserr.New(cip.ErrCodeNotAuthorizedException, "Invalid Access Token", nil)
+	}
+	u, ok := p.users[username]
+	if !ok {
+		return nil, awserr.New(cip.ErrCodeUserNotFoundException, "User does not exist.", nil)
+	}
+	return u, nil
+}
+
+func (p *Fake) group(name *string) (*cip.GroupType, error) {
+	group, ok := p.groups[aws.StringValue(name)]
+	if !ok {
+		return nil, awserr.New(cip.ErrCodeResourceNotFoundException, "Group not found.", nil)
+	}
+	return group, nil
+}
+
+func (p *Fake) usernames() []string {
+	names := make([]string, 0, len(p.users))
+	for name := range p.users {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (p *Fake) sortedGroups(include func(string) bool) []*cip.GroupType {
+	var groups []*cip.GroupType
+	for name, group := range p.groups {
+		if include(name) {
+			groups = append(groups, copyGroup(group))
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return *groups[i].GroupName < *groups[j].GroupName
+	})
+	return groups
+}
+
+// authenticated issues tokens or MFA challenge once the password was verified.
+func (p *Fake) authenticated(u *fakeUser) *fakeAuthResult {
+	switch {
+	case u.preferredMFA != "":
+		return p.challenge(u, u.preferredMFA)
+	case u.mfaEnabled[cip.ChallengeNameTypeSmsMfa] && u.mfaEnabled[cip.ChallengeNameTypeSoftwareTokenMfa]:
+		return p.challenge(u, cip.ChallengeNameTypeSelectMfaType)
+	case u.mfaEnabled[cip.ChallengeNameTypeSmsMfa]:
+		return p.challenge(u, cip.ChallengeNameTypeSmsMfa)
+	case u.mfaEnabled[cip.ChallengeNameTypeSoftwareTokenMfa]:
+		return p.challenge(u, cip.ChallengeNameTypeSoftwareTokenMfa)
+	}
+	return p.issueTokens(u)
+}
+
+func (p *Fake) challenge(u *fakeUser, name string) *fakeAuthResult {
+	id := randomID()
+	session := &fakeSession{username: u.username, challenge: name}
+	params := map[string]*string{"USER_ID_FOR_SRP": aws.String(u.username)}
+
+	switch name {
+	case cip.ChallengeNameTypeSmsMfa:
+		session.code = p.sendCode(u.username)
+		params["CODE_DELIVERY_DELIVERY_MEDIUM"] = aws.String(cip.DeliveryMediumTypeSms)
+		params["CODE_DELIVERY_DESTINATION"] = aws.String(mask(u.attributes["phone_number"]))
+	case cip.ChallengeNameTypeSelectMfaType:
+		params["MFAS_CAN_CHOOSE"] = aws.String(`["SMS_MFA","SOFTWARE_TOKEN_MFA"]`)
+	case cip.ChallengeNameTypeNewPasswordRequired:
+		params["requiredAttributes"] = aws.String("[]")
+		params["userAttributes"] = aws.String("{}")
+	}
+
+	p.sessions[id] = session
+	return &fakeAuthResult{challengeName: &name, params: params, session: &id}
+}
+
+// sendCode generates a new code and remembers it as the last one sent to the user.
+func (p *Fake) sendCode(username string) string {
+	code := randomDigits(6)
+	p.codes[username] = code
+	return code
+}
+
+func (p *Fake) issueTokens(u *fakeUser) *fakeAuthResult {
+	access, id := p.issueAccessTokens(u.username)
+	refresh := "refresh-" + randomID()
+	p.refreshTokens[refresh] = u.username
+
+	return &fakeAuthResult{tokens: &cip.AuthenticationResultType{
+		AccessToken:  &access,
+		ExpiresIn:    aws.Int64(fakeTokenExpiresIn),
+		IdToken:      &id,
+		RefreshToken: &refresh,
+		TokenType:    aws.String("Bearer"),
+	}}
+}
+
+func (p *Fake) issueAccessTokens(username string) (access, id string) {
+	access, id = "access-"+randomID(), "id-"+randomID()
+	p.accessTokens[access] = username
+	return access, id
+}
+
+func (p *Fake) revoke(username string) {
+	for token, name := range p.accessTokens {
+		if name == username {
+			delete(p.accessTokens, token)
+		}
+	}
+	for token, name := range p.refreshTokens {
+		if name == username {
+			delete(p.refreshTokens, token)
+		}
+	}
+}
+
+type fakeAuthResult struct {
+	tokens        *cip.AuthenticationResultType
+	challengeName *string
+	params        map[string]*string
+	session       *string
+}
+
+func (r *fakeAuthResult) adminInitiateAuthOutput() *cip.AdminInitiateAuthOutput {
+	return &cip.AdminInitiateAuthOutput{
+		AuthenticationResult: r.tokens,
+		ChallengeName:        r.challengeName,
+		ChallengeParameters:  r.params,
+		Session:              r.session,
+	}
+}
+
+func (r *fakeAuthResult) adminRespondToAuthChallengeOutput() *cip.AdminRespondToAuthChallengeOutput {
+	return &cip.AdminRespondToAuthChallengeOutput{
+		AuthenticationResult: r.tokens,
+		ChallengeName:        r.challengeName,
+		ChallengeParameters:  r.params,
+		Session:              r.session,
+	}
+}
+
+func (u *fakeUser) userType() *cip.UserType {
+	return &cip.UserType{
+		Attributes:           toAttributes(u.attributes),
+		Enabled:              aws.Bool(u.enabled),
+		UserCreateDate:       aws.Time(u.created),
+		UserLastModifiedDate: aws.Time(u.modified),
+		UserStatus:           aws.String(u.status),
+		Username:             aws.String(u.username),
+	}
+}
+
+func (u *fakeUser) codeDelivery() *cip.CodeDeliveryDetailsType {
+	if phone, ok := u.attributes["phone_number"]; ok && u.attributes["email"] == "" {
+		return &cip.CodeDeliveryDetailsType{
+			AttributeName:  aws.String("phone_number"),
+			DeliveryMedium: aws.String(cip.DeliveryMediumTypeSms),
+			Destination:    aws.String(mask(phone)),
+		}
+	}
+	return &cip.CodeDeliveryDetailsType{
+		AttributeName:  aws.String("email"),
+		DeliveryMedium: aws.String(cip.DeliveryMediumTypeEmail),
+		Destination:    aws.String(mask(u.attributes["email"])),
+	}
+}
+
+func (u *fakeUser) preferred() *string {
+	if u.preferredMFA == "" {
+		return nil
+	}
+	return aws.String(u.preferredMFA)
+}
+
+func (u *fakeUser) mfaSettings() []*string {
+	var settings []*string
+	for _, name := range []string{cip.ChallengeNameTypeSmsMfa, cip.ChallengeNameTypeSoftwareTokenMfa} {
+		if u.mfaEnabled[name] {
+			settings = append(settings, aws.String(name))
+		}
+	}
+	return settings
+}
+
+func (u *fakeUser) setMFAPreference(sms *cip.SMSMfaSettingsType, token *cip.SoftwareTokenMfaSettingsType) error {
+	if token != nil && aws.BoolValue(token.Enabled) && !u.totpVerified {
+		return awserr.New(cip.ErrCodeInvalidParameterException, "User has not verified software token mfa", nil)
+	}
+	if sms != nil && aws.BoolValue(sms.Enabled) && u.attributes["phone_number"] == "" {
+		return awserr.New(cip.ErrCodeInvalidParameterException, "User does not have delivery config set to turn on SMS_MFA", nil)
+	}
+
+	apply := func(name string, enabled, preferred *bool) {
+		u.mfaEnabled[name] = aws.BoolValue(enabled)
+		if aws.BoolValue(enabled) && aws.BoolValue(preferred) {
+			u.preferredMFA = name
+		} else if u.preferredMFA == name {
+			u.preferredMFA = ""
+		}
+	}
+	if sms != nil {
+		apply(cip.ChallengeNameTypeSmsMfa, sms.Enabled, sms.PreferredMfa)
+	}
+	if token != nil {
+		apply(cip.ChallengeNameTypeSoftwareTokenMfa, token.Enabled, token.PreferredMfa)
+	}
+	return nil
+}
+
+// parseFilter supports the = and ^= operators of ListUsers filter, e.g. email ^= "john".
+func parseFilter(filter string) (func(*fakeUser) bool, error) {
+	if strings.TrimSpace(filter) == "" {
+		return func(*fakeUser) bool { return true }, nil
+	}
+
+	var name, operator, value string
+	for _, op := range []string{"^=", "="} {
+		if i := strings.Index(filter, op); i > 0 {
+			name, operator, value = strings.TrimSpace(filter[:i]), op, strings.TrimSpace(filter[i+len(op):])
+			break
+		}
+	}
+	unquoted, err := strconv.Unquote(value)
+	if operator == "" || err != nil {
+		return nil, awserr.New(cip.ErrCodeInvalidParameterException, "Error while parsing filter.", nil)
+	}
+
+	return func(u *fakeUser) bool {
+		var actual string
+		switch name {
+		case "username":
+			actual = u.username
+		case "cognito:user_status":
+			actual = u.status
+		case "status":
+			actual = "Disabled"
+			if u.enabled {
+				actual = "Enabled"
+			}
+		default:
+			actual = u.attributes[name]
+		}
+		if operator == "^=" {
+			return strings.HasPrefix(actual, unquoted)
+		}
+		return actual == unquoted
+	}, nil
+}
+
+// paginate returns bounds of the page and token of the next one, tokens are plain offsets.
+func paginate(total int, limit *int64, token *string) ([2]int, *string, error) {
+	start := 0
+	if t := aws.StringValue(token); t != "" {
+		var err error
+		if start, err = strconv.Atoi(t); err != nil || start > total {
+			return [2]int{}, nil, awserr.New(cip.ErrCodeInvalidParameterException, "Invalid pagination token.", nil)
+		}
+	}
+	size := int(aws.Int64Value(limit))
+	if size <= 0 {
+		size = fakeDefaultPageLimit
+	}
+
+	end := start + size
+	if end >= total {
+		return [2]int{start, total}, nil, nil
+	}
+	return [2]int{start, end}, aws.String(strconv.Itoa(end)), nil
+}
+
+func checkPassword(password string) error {
+	if len(password) < fakeMinPasswordLen {
+		return awserr.New(cip.ErrCodeInvalidPasswordException, "Password did not conform with policy: Password not long enough", nil)
+	}
+	return nil
+}
+
+func copyGroup(group *cip.GroupType) *cip.GroupType {
+	copied := *group
+	return &copied
+}
+
+func codeMismatch() error {
+	return awserr.New(cip.ErrCodeCodeMismatchException, "Invalid verification code provided, please try again.", nil)
+}
+
+func notAuthorized() error {
+	return awserr.New(cip.ErrCodeNotAuthorizedException, "Incorrect username or password.", nil)
+}
+
+func userDisabled() error {
+	return awserr.New(cip.ErrCodeNotAuthorizedException, "User is disabled.", nil)
+}
+
+func mask(destination string) string {
+	if destination == "" {
+		return ""
+	}
+	if i := strings.Index(destination, "@"); i > 0 {
+		return destination[:1] + "***" + destination[i:]
+	}
+	if len(destination) > 4 {
+		return "+*******" + destination[len(destination)-4:]
+	}
+	return destination
+}
+
+func fromAttributes(attrs []*cip.AttributeType) map[string]string {
+	attributes := make(map[string]string)
+	for _, attr := range attrs {
+		if attr.Name != nil && attr.Value != nil {
+			attributes[*attr.Name] = *attr.Value
+		}
+	}
+	return attributes
+}
+
+func toAttributes(attributes map[string]string) []*cip.AttributeType {
+	attrs := make([]*cip.AttributeType, 0, len(attributes))
+	for name, value := range attributes {
+		attrs = append(attrs, attribute(name, value))
+	}
+	return attrs
+}
+
+func attribute(name, value string) *cip.AttributeType {
+	return &cip.AttributeType{Name: &name, Value: &value}
+}
+
+func parameter(params map[string]*string, name string) string {
+	if v := params[name]; v != nil {
+		return *v
+	}
+	return ""
+}
+
+// totp computes RFC 6238 code with default parameters used by Cognito: SHA1, 6 digits and 30 seconds period.
+func totp(secret string, t time.Time) string {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		return ""
+	}
+
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(t.Unix()/totpPeriod))
+	mac := hmac.New(sha1.New, key)
+	_, _ = mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", code%1000000)
+}
+
+func randomDigits(n int) string {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+	v, _ := rand.Int(rand.Reader, max)
+	return fmt.Sprintf("%0*d", n, v)
+}
+
+func randomID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func randomUUID() string {
+	id := randomID()
+	return id[:8] + "-" + id[8:12] + "-" + id[12:16] + "-" + id[16:20] + "-" + id[20:]
+}
@@ -0,0 +1,277 @@
+package cognitotest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Ryanair/goaws/cognito"
+
+	"github.com/aws/aws-sdk-go/aws"
+	cip "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	poolID      = "abc-def-pool-id"
+	clientID    = "ghi-jklm-client-id"
+	username    = "John"
+	email       = "john@example.com"
+	emailAttr   = "email"
+	oldPassword = "oldSecret"
+	newPassword = "newSecret"
+)
+
+func toAwsgoError(t *testing.T, err error) cognito.Error {
+	awsgoError, ok := err.(cognito.Error)
+	if !ok {
+		t.Errorf("invalid error type, expected Error")
+	}
+	return awsgoError
+}
+
+func TestFake_signUpAndSignIn(t *testing.T) {
+
+	// given
+	pool := NewFake(poolID)
+	adapter := pool.Adapter(clientID)
+
+	// when
+	_, err := adapter.SignUp(username, oldPassword, map[string]string{emailAttr: email}, nil)
+	assert.NoError(t, err)
+	_, notConfirmedErr := adapter.SignIn(username, oldPassword)
+	code, _ := pool.Code(username)
+	mismatchErr := adapter.ConfirmSignUp(username, "wrong")
+	confirmErr := adapter.ConfirmSignUp(username, code)
+	result, signInErr := adapter.SignIn(username, oldPassword)
+
+	// then
+	assert.True(t, toAwsgoError(t, notConfirmedErr).UserNotConfirmed())
+	assert.True(t, toAwsgoError(t, mismatchErr).CodeMismatch())
+	assert.NoError(t, confirmErr)
+	assert.NoError(t, signInErr)
+	assert.NotEmpty(t, aws.StringValue(result.AuthenticationResult.AccessToken))
+
+	user, err := adapter.GetUser(aws.StringValue(result.AuthenticationResult.AccessToken))
+	assert.NoError(t, err)
+	assert.Equal(t, email, user.UserAttributes[emailAttr])
+}
+
+func TestFake_signInErrors(t *testing.T) {
+
+	// given
+	adapter := NewFake(poolID).Adapter(clientID)
+	_, err := adapter.CreateUser(username, oldPassword, nil, nil, false)
+	assert.NoError(t, err)
+	assert.NoError(t, adapter.AdminSetUserPassword(username, oldPassword))
+	assert.NoError(t, adapter.AdminDisableUser(username))
+
+	// when
+	_, existsErr := adapter.SignUp(username, oldPassword, nil, nil)
+	_, notFoundErr := adapter.SignIn("unknown", oldPassword)
+	_, wrongPasswordErr := adapter.SignIn(username, "wrong-password")
+	_, disabledErr := adapter.SignIn(username, oldPassword)
+	_, invalidPasswordErr := adapter.SignUp("Jane", "short", nil, nil)
+
+	// then
+	assert.True(t, toAwsgoError(t, existsErr).UsernameExists())
+	assert.True(t, toAwsgoError(t, notFoundErr).UserNotFound())
+	assert.True(t, toAwsgoError(t, wrongPasswordErr).NotAuthorized())
+	assert.True(t, toAwsgoError(t, disabledErr).NotAuthorized())
+	assert.True(t, toAwsgoError(t, invalidPasswordErr).InvalidPassword())
+}
+
+func TestFake_temporaryPassword(t *testing.T) {
+
+	// given
+	adapter := NewFake(poolID).Adapter(clientID)
+	_, err := adapter.CreateUser(username, oldPassword, map[string]string{emailAttr: email}, nil, false)
+	assert.NoError(t, err)
+
+	// when
+	challenge, err := adapter.SignIn(username, oldPassword)
+	assert.NoError(t, err)
+	result, respondErr := adapter.RespondToChallenge(username, challenge.Session, &cognito.NewPasswordResponse{NewPassword: newPassword})
+	_, reusedSessionErr := adapter.RespondToChallenge(username, challenge.Session, &cognito.NewPasswordResponse{NewPassword: newPassword})
+
+	// then
+	assert.Equal(t, cip.ChallengeNameTypeNewPasswordRequired, aws.StringValue(challenge.ChallengeName))
+	assert.NoError(t, respondErr)
+	assert.NotNil(t, result.AuthenticationResult)
+	assert.True(t, toAwsgoError(t, reusedSessionErr).NotAuthorized())
+
+	user, err := adapter.AdminGetUser(username)
+	assert.NoError(t, err)
+	assert.Equal(t, cip.UserStatusTypeConfirmed, aws.StringValue(user.UserStatus))
+}
+
+func TestFake_resetPassword(t *testing.T) {
+
+	// given
+	pool := NewFake(poolID)
+	adapter := pool.Adapter(clientID)
+	_, err := adapter.CreateUser(username, oldPassword, nil, nil, false)
+	assert.NoError(t, err)
+	assert.NoError(t, adapter.ResetUserPassword(username))
+
+	// when
+	_, resetRequiredErr := adapter.SignIn(username, oldPassword)
+	code, ok := pool.Code(username)
+	mismatchErr := adapter.ConfirmForgotPassword(username, newPassword, "wrong")
+	confirmErr := adapter.ConfirmForgotPassword(username, newPassword, code)
+	_, signInErr := adapter.SignIn(username, newPassword)
+
+	// then
+	assert.True(t, toAwsgoError(t, resetRequiredErr).PasswordResetRequired())
+	assert.True(t, ok)
+	assert.True(t, toAwsgoError(t, mismatchErr).CodeMismatch())
+	assert.NoError(t, confirmErr)
+	assert.NoError(t, signInErr)
+}
+
+func TestFake_tokens(t *testing.T) {
+
+	// given
+	adapter := NewFake(poolID).Adapter(clientID)
+	_, err := adapter.CreateUser(username, oldPassword, nil, nil, false)
+	assert.NoError(t, err)
+	assert.NoError(t, adapter.AdminSetUserPassword(username, oldPassword))
+	signIn, err := adapter.SignIn(username, oldPassword)
+	assert.NoError(t, err)
+	tokens := signIn.AuthenticationResult
+
+	// when
+	refreshed, refreshErr := adapter.RefreshTokens(username, aws.StringValue(tokens.RefreshToken))
+	revokeErr := adapter.RevokeTokens(aws.StringValue(refreshed.AccessToken))
+	_, getUserErr := adapter.GetUser(aws.StringValue(tokens.AccessToken))
+	_, refreshRevokedErr := adapter.RefreshTokens(username, aws.StringValue(tokens.RefreshToken))
+
+	// then
+	assert.NoError(t, refreshErr)
+	assert.NotEqual(t, aws.StringValue(tokens.AccessToken), aws.StringValue(refreshed.AccessToken))
+	assert.NoError(t, revokeErr)
+	assert.True(t, toAwsgoError(t, getUserErr).NotAuthorized())
+	assert.True(t, toAwsgoError(t, refreshRevokedErr).NotAuthorized())
+}
+
+func TestFake_listUsers(t *testing.T) {
+
+	// given
+	adapter := NewFake(poolID).Adapter(clientID)
+	for name, mail := range map[string]string{"John": "john@example.com", "Jane": "jane@example.com", "Bob": "bob@test.com"} {
+		_, err := adapter.CreateUser(name, oldPassword, map[string]string{emailAttr: mail}, nil, false)
+		assert.NoError(t, err)
+	}
+
+	// when
+	users, err := adapter.ListUsers(cognito.FilterStartsWith(emailAttr, "j"), func(in *cip.ListUsersInput) { in.Limit = aws.Int64(1) })
+
+	// then
+	assert.NoError(t, err)
+	assert.Len(t, users, 2)
+	assert.Equal(t, "Jane", aws.StringValue(users[0].Username))
+	assert.Equal(t, "John", aws.StringValue(users[1].Username))
+}
+
+func TestFake_groups(t *testing.T) {
+
+	// given
+	adapter := NewFake(poolID).Adapter(clientID)
+	_, err := adapter.CreateUser(username, oldPassword, nil, nil, false)
+	assert.NoError(t, err)
+	_, err = adapter.CreateGroup("admins", cognito.GroupPrecedence(1))
+	assert.NoError(t, err)
+
+	// when
+	_, existsErr := adapter.CreateGroup("admins")
+	_, updateErr := adapter.UpdateGroup("admins", cognito.GroupDescription("Administrators"))
+	addErr := adapter.AdminAddUserToGroup(username, "admins")
+	missingGroupErr := adapter.AdminAddUserToGroup(username, "unknown")
+	groups, listErr := adapter.AdminListGroupsForUser(username)
+	members, membersErr := adapter.ListUsersInGroup("admins")
+
+	// then
+	assert.True(t, toAwsgoError(t, existsErr).GroupExists())
+	assert.NoError(t, updateErr)
+	assert.NoError(t, addErr)
+	assert.Equal(t, cip.ErrCodeResourceNotFoundException, toAwsgoError(t, missingGroupErr).Code)
+	assert.NoError(t, listErr)
+	assert.Len(t, groups, 1)
+	assert.Equal(t, int64(1), aws.Int64Value(groups[0].Precedence))
+	assert.Equal(t, "Administrators", aws.StringValue(groups[0].Description))
+	assert.NoError(t, membersErr)
+	assert.Len(t, members, 1)
+}
+
+func TestFake_softwareTokenMFA(t *testing.T) {
+
+	// given
+	pool := NewFake(poolID)
+	adapter := pool.Adapter(clientID)
+	_, err := adapter.CreateUser(username, oldPassword, nil, nil, false)
+	assert.NoError(t, err)
+	assert.NoError(t, adapter.AdminSetUserPassword(username, oldPassword))
+	signIn, err := adapter.SignIn(username, oldPassword)
+	assert.NoError(t, err)
+	accessToken := aws.StringValue(signIn.AuthenticationResult.AccessToken)
+
+	// when
+	_, err = adapter.AssociateSoftwareToken(accessToken)
+	assert.NoError(t, err)
+	_, wrongCodeErr := adapter.VerifySoftwareToken(accessToken, "000000x", "phone")
+	code, _ := pool.SoftwareTokenCode(username)
+	_, verifyErr := adapter.VerifySoftwareToken(accessToken, code, "phone")
+	preferenceErr := adapter.SetUserMFAPreference(accessToken, cognito.MFAPreference{SoftwareToken: &cognito.MFASetting{Enabled: true, Preferred: true}})
+	challenge, signInErr := adapter.SignIn(username, oldPassword)
+	_, mismatchErr := adapter.RespondToChallenge(username, challenge.Session, &cognito.SoftwareTokenMFAResponse{Code: "000000x"})
+	code, _ = pool.SoftwareTokenCode(username)
+	result, respondErr := adapter.RespondToChallenge(username, challenge.Session, &cognito.SoftwareTokenMFAResponse{Code: code})
+
+	// then
+	assert.Equal(t, cip.ErrCodeEnableSoftwareTokenMFAException, toAwsgoError(t, wrongCodeErr).Code)
+	assert.NoError(t, verifyErr)
+	assert.NoError(t, preferenceErr)
+	assert.NoError(t, signInErr)
+	assert.Equal(t, cip.ChallengeNameTypeSoftwareTokenMfa, aws.StringValue(challenge.ChallengeName))
+	assert.True(t, toAwsgoError(t, mismatchErr).CodeMismatch())
+	assert.NoError(t, respondErr)
+	assert.NotNil(t, result.AuthenticationResult)
+}
+
+func TestFake_latestCode(t *testing.T) {
+
+	// given
+	pool := NewFake(poolID)
+	adapter := pool.Adapter(clientID)
+	_, err := adapter.CreateUser(username, oldPassword, map[string]string{"phone_number": "+48123456789"}, nil, false)
+	assert.NoError(t, err)
+	assert.NoError(t, adapter.AdminSetUserPassword(username, oldPassword))
+	assert.NoError(t, adapter.AdminSetUserMFAPreference(username, cognito.MFAPreference{SMS: &cognito.MFASetting{Enabled: true}}))
+
+	// when
+	var challenges []*cognito.SignInResult
+	for i := 0; i < 5; i++ {
+		challenge, err := adapter.SignIn(username, oldPassword)
+		assert.NoError(t, err)
+		challenges = append(challenges, challenge)
+	}
+	code, ok := pool.Code(username)
+	result, respondErr := adapter.RespondToChallenge(username, challenges[4].Session, cognito.SMSMFAResponse{Code: code})
+
+	// then
+	assert.True(t, ok)
+	assert.Equal(t, cip.ChallengeNameTypeSmsMfa, aws.StringValue(challenges[4].ChallengeName))
+	assert.NoError(t, respondErr)
+	assert.NotNil(t, result.AuthenticationResult)
+}
+
+func TestTotp(t *testing.T) {
+
+	// given RFC 6238 test vector for SHA1, secret "12345678901234567890" base32 encoded
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+	// when
+	code := totp(secret, time.Unix(59, 0))
+
+	// then
+	assert.Equal(t, "287082", code)
+}
@@ -0,0 +1,198 @@
+package cognito
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"math/big"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/pkg/errors"
+)
+
+const (
+	// srpN is the 3072-bit prime of RFC 3526 used by Cognito SRP, the generator is 2
+	srpN = "FFFFFFFFFFFFFFFFC90FDAA22168C234C4C6628B80DC1CD1" +
+		"29024E088A67CC74020BBEA63B139B22514A08798E3404DD" +
+		"EF9519B3CD3A431B302B0A6DF25F14374FE1356D6D51C245" +
+		"E485B576625E7EC6F44C42E9A637ED6B0BFF5CB6F406B7ED" +
+		"EE386BFB5A899FA5AE9F24117C4B1FE649286651ECE45B3D" +
+		"C2007CB8A163BF0598DA48361C55D39A69163FA8FD24CF5F" +
+		"83655D23DCA3AD961C62F356208552BB9ED529077096966D" +
+		"670C354E4ABC9804F1746C08CA18217C32905E462E36CE3B" +
+		"E39E772C180E86039B2783A2EC07A28FB5C55DF06F4C52C9" +
+		"DE2BCBF6955817183995497CEA956AE515D2261898FA0510" +
+		"15728E5A8AAAC42DAD33170D04507A33A85521ABDF1CBA64" +
+		"ECFB850458DBEF0A8AEA71575D060C7DB3970F85A6E1E4C7" +
+		"ABF5AE8CDB0933D71E8C94E04A25619DCEE3D2261AD2EE6B" +
+		"F12FFA06D98A0864D87602733EC86A64521F2B18177B200C" +
+		"BBE117577A615D6C770988C0BAD946E208E24FA074E5AB31" +
+		"43DB5BFCE0FD108E4B82D120A93AD2CAFFFFFFFFFFFFFFFF"
+	srpInfo         = "Caldera Derived Key"
+	timestampFormat = "Mon Jan 2 15:04:05 UTC 2006"
+)
+
+var (
+	srpPrime, _ = new(big.Int).SetString(srpN, 16)
+	srpG        = big.NewInt(2)
+	srpK        = hashInts(srpPrime, srpG)
+)
+
+// SignInDeviceKey signs in with a remembered device, Cognito answers with DEVICE_SRP_AUTH challenge instead of tokens.
+func SignInDeviceKey(deviceKey string) func(*cognitoidentityprovider.AdminInitiateAuthInput) {
+	return func(in *cognitoidentityprovider.AdminInitiateAuthInput) {
+		in.AuthParameters["DEVICE_KEY"] = &deviceKey
+	}
+}
+
+// DeviceSRP computes responses to DEVICE_SRP_AUTH and DEVICE_PASSWORD_VERIFIER challenges of a remembered device. Both
+// responses share an ephemeral secret, so a new DeviceSRP has to be created for every sign in.
+type DeviceSRP struct {
+	deviceKey      string
+	deviceGroupKey string
+	devicePassword string
+	a              *big.Int
+	bigA           *big.Int
+	now            func() time.Time
+}
+
+// DeviceSRPAuthResponse starts authentication of the device, it answers DEVICE_SRP_AUTH.
+type DeviceSRPAuthResponse struct {
+	DeviceKey string
+	SRPA      string
+}
+
+// DevicePasswordVerifierResponse proves the device knows its password, it answers DEVICE_PASSWORD_VERIFIER.
+type DevicePasswordVerifierResponse struct {
+	DeviceKey   string
+	SecretBlock string
+	Timestamp   string
+	Signature   string
+}
+
+// NewDeviceSRP starts device authentication with keys and password remembered when the device was confirmed.
+func NewDeviceSRP(deviceKey, deviceGroupKey, devicePassword string) (*DeviceSRP, error) {
+	secret := make([]byte, 128)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, wrapErrWithCode(err, "error in cognito.DeviceSRP while generating secret", ErrCodeDeviceAuth)
+	}
+
+	a := new(big.Int).Mod(new(big.Int).SetBytes(secret), srpPrime)
+	return &DeviceSRP{
+		deviceKey:      deviceKey,
+		deviceGroupKey: deviceGroupKey,
+		devicePassword: devicePassword,
+		a:              a,
+		bigA:           new(big.Int).Exp(srpG, a, srpPrime),
+		now:            time.Now,
+	}, nil
+}
+
+func (d *DeviceSRP) AuthResponse() DeviceSRPAuthResponse {
+	return DeviceSRPAuthResponse{DeviceKey: d.deviceKey, SRPA: d.bigA.Text(16)}
+}
+
+// PasswordVerifier signs SECRET_BLOCK of DEVICE_PASSWORD_VERIFIER challenge with the key derived from SRP_B and SALT.
+func (d *DeviceSRP) PasswordVerifier(challenge *Challenge) (DevicePasswordVerifierResponse, error) {
+	const msg = "error in cognito.DeviceSRP while computing password verifier"
+
+	b, ok := new(big.Int).SetString(parameter(challenge.Parameters, "SRP_B"), 16)
+	if !ok || new(big.Int).Mod(b, srpPrime).Sign() == 0 {
+		return DevicePasswordVerifierResponse{}, wrapErrWithCode(errors.New("invalid SRP_B"), msg, ErrCodeDeviceAuth)
+	}
+	salt, ok := new(big.Int).SetString(parameter(challenge.Parameters, "SALT"), 16)
+	if !ok {
+		return DevicePasswordVerifierResponse{}, wrapErrWithCode(errors.New("invalid SALT"), msg, ErrCodeDeviceAuth)
+	}
+	secretBlock := parameter(challenge.Parameters, "SECRET_BLOCK")
+	block, err := base64.StdEncoding.DecodeString(secretBlock)
+	if err != nil {
+		return DevicePasswordVerifierResponse{}, wrapErrWithCode(err, msg, ErrCodeDeviceAuth)
+	}
+
+	u := hashInts(d.bigA, b)
+	if u.Sign() == 0 {
+		return DevicePasswordVerifierResponse{}, wrapErrWithCode(errors.New("invalid SRP_B"), msg, ErrCodeDeviceAuth)
+	}
+
+	// S = (B - k * g^x) ^ (a + u * x) mod N
+	x := d.x(salt)
+	base := new(big.Int).Mul(srpK, new(big.Int).Exp(srpG, x, srpPrime))
+	base.Sub(b, base).Mod(base, srpPrime)
+	exp := new(big.Int).Mul(u, x)
+	exp.Add(exp, d.a)
+	s := new(big.Int).Exp(base, exp, srpPrime)
+
+	timestamp := d.now().UTC().Format(timestampFormat)
+	mac := hmac.New(sha256.New, deriveKey(s, u))
+	mac.Write([]byte(d.deviceGroupKey + d.deviceKey))
+	mac.Write(block)
+	mac.Write([]byte(timestamp))
+
+	return DevicePasswordVerifierResponse{
+		DeviceKey:   d.deviceKey,
+		SecretBlock: secretBlock,
+		Timestamp:   timestamp,
+		Signature:   base64.StdEncoding.EncodeToString(mac.Sum(nil)),
+	}, nil
+}
+
+// x computes private key of the device, H(salt | H(deviceGroupKey | deviceKey | ":" | devicePassword)).
+func (d *DeviceSRP) x(salt *big.Int) *big.Int {
+	identity := sha256.Sum256([]byte(d.deviceGroupKey + d.deviceKey + ":" + d.devicePassword))
+	h := sha256.New()
+	h.Write(padInt(salt))
+	h.Write(identity[:])
+	return new(big.Int).SetBytes(h.Sum(nil))
+}
+
+func (DeviceSRPAuthResponse) challengeName() string {
+	return cognitoidentityprovider.ChallengeNameTypeDeviceSrpAuth
+}
+
+func (r DeviceSRPAuthResponse) challengeResponses() map[string]*string {
+	return map[string]*string{"DEVICE_KEY": &r.DeviceKey, "SRP_A": &r.SRPA}
+}
+
+func (DevicePasswordVerifierResponse) challengeName() string {
+	return cognitoidentityprovider.ChallengeNameTypeDevicePasswordVerifier
+}
+
+func (r DevicePasswordVerifierResponse) challengeResponses() map[string]*string {
+	return map[string]*string{
+		"DEVICE_KEY":                  &r.DeviceKey,
+		"PASSWORD_CLAIM_SECRET_BLOCK": &r.SecretBlock,
+		"PASSWORD_CLAIM_SIGNATURE":    &r.Signature,
+		"TIMESTAMP":                   &r.Timestamp,
+	}
+}
+
+// deriveKey is HKDF-SHA256 of the shared secret salted with u, truncated to 16 bytes as Cognito expects.
+func deriveKey(s, u *big.Int) []byte {
+	extract := hmac.New(sha256.New, padInt(u))
+	extract.Write(padInt(s))
+	expand := hmac.New(sha256.New, extract.Sum(nil))
+	expand.Write([]byte(srpInfo))
+	expand.Write([]byte{1})
+	return expand.Sum(nil)[:16]
+}
+
+func hashInts(values ...*big.Int) *big.Int {
+	h := sha256.New()
+	for _, v := range values {
+		h.Write(padInt(v))
+	}
+	return new(big.Int).SetBytes(h.Sum(nil))
+}
+
+// padInt encodes the value as big-endian bytes with a leading zero byte when the highest bit is set, so it is never
+// read back as negative, which matches hex padding of Cognito SDKs.
+func padInt(v *big.Int) []byte {
+	b := v.Bytes()
+	if len(b) == 0 || b[0]&0x80 != 0 {
+		return append([]byte{0}, b...)
+	}
+	return b
+}
@@ -0,0 +1,81 @@
+package cognito
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	cip "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceSRP_PasswordVerifier(t *testing.T) {
+
+	// given the verifier stored by Cognito when the device was confirmed
+	srp, err := NewDeviceSRP("eu-west-1_device", "-group-key", "device-password")
+	assert.NoError(t, err)
+	srp.now = func() time.Time { return time.Date(2020, 3, 7, 9, 5, 1, 0, time.FixedZone("CET", 3600)) }
+
+	salt := big.NewInt(0).SetBytes([]byte("device-salt-0123"))
+	v := new(big.Int).Exp(srpG, srp.x(salt), srpPrime)
+	b := big.NewInt(0).SetBytes([]byte("server-ephemeral-secret"))
+	bigB := new(big.Int).Mul(srpK, v)
+	bigB.Add(bigB, new(big.Int).Exp(srpG, b, srpPrime)).Mod(bigB, srpPrime)
+	secretBlock := base64.StdEncoding.EncodeToString([]byte("secret-block"))
+
+	// when
+	auth := srp.AuthResponse()
+	result := &SignInResult{
+		ChallengeName: aws.String(cip.ChallengeNameTypeDevicePasswordVerifier),
+		ChallengeParameters: aws.StringMap(map[string]string{
+			"USERNAME":     "user-id",
+			"SRP_B":        bigB.Text(16),
+			"SALT":         salt.Text(16),
+			"SECRET_BLOCK": secretBlock,
+		}),
+	}
+	verifier, err := srp.PasswordVerifier(result.Challenge())
+
+	// then Cognito computes the same key, S = (A * v^u) ^ b mod N
+	bigA, _ := new(big.Int).SetString(auth.SRPA, 16)
+	u := hashInts(bigA, bigB)
+	s := new(big.Int).Exp(v, u, srpPrime)
+	s.Mul(s, bigA).Exp(s, b, srpPrime)
+	mac := hmac.New(sha256.New, deriveKey(s, u))
+	mac.Write([]byte("-group-keyeu-west-1_device"))
+	mac.Write([]byte("secret-block"))
+	mac.Write([]byte("Sat Mar 7 08:05:01 UTC 2020"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "user-id", result.Challenge().Username)
+	assert.Equal(t, "eu-west-1_device", auth.DeviceKey)
+	assert.Equal(t, "eu-west-1_device", verifier.DeviceKey)
+	assert.Equal(t, secretBlock, verifier.SecretBlock)
+	assert.Equal(t, "Sat Mar 7 08:05:01 UTC 2020", verifier.Timestamp)
+	assert.Equal(t, base64.StdEncoding.EncodeToString(mac.Sum(nil)), verifier.Signature)
+}
+
+func TestDeviceSRP_PasswordVerifier_invalidB(t *testing.T) {
+
+	// given
+	srp, err := NewDeviceSRP("eu-west-1_device", "-group-key", "device-password")
+	assert.NoError(t, err)
+	challenge := &Challenge{
+		Name: cip.ChallengeNameTypeDevicePasswordVerifier,
+		Parameters: aws.StringMap(map[string]string{
+			"SRP_B":        srpPrime.Text(16),
+			"SALT":         "abc",
+			"SECRET_BLOCK": "",
+		}),
+	}
+
+	// when
+	_, err = srp.PasswordVerifier(challenge)
+
+	// then
+	assert.True(t, toAwsgoError(t, err).DeviceAuthFailed())
+}
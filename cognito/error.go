@@ -11,6 +11,13 @@ const (
 	ErrCodeSignIn                 = "SignInErr"
 	ErrCodeRespondToAuthChallenge = "RespondToAuthChallengeErr"
 	ErrCodeChangePasswordRequest  = "ChangePasswordRequestErr"
+	ErrCodeRefreshTokens          = "RefreshTokensErr"
+	ErrCodeInvalidToken           = "InvalidTokenErr"
+	ErrCodeTokenExpired           = "TokenExpiredErr"
+	ErrCodeJWKSFetch              = "JWKSFetchErr"
+	ErrCodeVerifySoftwareToken    = "VerifySoftwareTokenErr"
+	ErrCodeChallengeRequired      = "ChallengeRequiredErr"
+	ErrCodeDeviceAuth             = "DeviceAuthErr"
 )
 
 type Error internal.Error
@@ -91,6 +98,35 @@ func (e Error) ChangePasswordRequestFailed() bool {
 	return internal.AnyEquals(e.Code, ErrCodeChangePasswordRequest)
 }
 
+func (e Error) RefreshTokensFailed() bool {
+	return internal.AnyEquals(e.Code, ErrCodeRefreshTokens)
+}
+
+func (e Error) InvalidToken() bool {
+	return internal.AnyEquals(e.Code, ErrCodeInvalidToken, ErrCodeTokenExpired)
+}
+
+func (e Error) TokenExpired() bool {
+	return internal.AnyEquals(e.Code, ErrCodeTokenExpired)
+}
+
+func (e Error) JWKSFetchFailed() bool {
+	return internal.AnyEquals(e.Code, ErrCodeJWKSFetch)
+}
+
+func (e Error) VerifySoftwareTokenFailed() bool {
+	return internal.AnyEquals(e.Code, ErrCodeVerifySoftwareToken)
+}
+
+// ChallengeRequired reports that Cognito asked for a challenge, e.g. MFA, instead of issuing tokens.
+func (e Error) ChallengeRequired() bool {
+	return internal.AnyEquals(e.Code, ErrCodeChallengeRequired)
+}
+
+func (e Error) DeviceAuthFailed() bool {
+	return internal.AnyEquals(e.Code, ErrCodeDeviceAuth)
+}
+
 func (e Error) InternalError() bool {
 	return internal.AnyEquals(e.Code, cognitoidentityprovider.ErrCodeConcurrentModificationException,
 		cognitoidentityprovider.ErrCodeDuplicateProviderException,
@@ -0,0 +1,240 @@
+package cognito
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+)
+
+type GroupResult struct {
+	GroupName        *string
+	Description      *string
+	Precedence       *int64
+	RoleArn          *string
+	CreationDate     *time.Time
+	LastModifiedDate *time.Time
+}
+
+type GroupConfig struct {
+	description *string
+	precedence  *int64
+	roleArn     *string
+}
+
+func GroupDescription(description string) func(*GroupConfig) {
+	return func(c *GroupConfig) {
+		c.description = &description
+	}
+}
+
+// GroupPrecedence decides which group's role is used in tokens when a user belongs to many groups, lower wins.
+func GroupPrecedence(precedence int64) func(*GroupConfig) {
+	return func(c *GroupConfig) {
+		c.precedence = &precedence
+	}
+}
+
+// GroupRoleArn sets IAM role assumed by group members through identity pools.
+func GroupRoleArn(roleArn string) func(*GroupConfig) {
+	return func(c *GroupConfig) {
+		c.roleArn = &roleArn
+	}
+}
+
+func (ca *Adapter) CreateGroup(groupName string, options ...func(*GroupConfig)) (*GroupResult, error) {
+
+	cfg := &GroupConfig{}
+	for _, opt := range options {
+		opt(cfg)
+	}
+
+	input := &cognitoidentityprovider.CreateGroupInput{
+		Description: cfg.description,
+		GroupName:   &groupName,
+		Precedence:  cfg.precedence,
+		RoleArn:     cfg.roleArn,
+		UserPoolId:  &ca.poolID,
+	}
+
+	output, err := ca.provider.CreateGroup(input)
+	if err != nil {
+		return nil, wrapErr(err, "error in cognito.Adapter while sending CreateGroupRequest")
+	}
+	return toGroupResult(output.Group), nil
+}
+
+func (ca *Adapter) GetGroup(groupName string) (*GroupResult, error) {
+
+	input := &cognitoidentityprovider.GetGroupInput{
+		GroupName:  &groupName,
+		UserPoolId: &ca.poolID,
+	}
+
+	output, err := ca.provider.GetGroup(input)
+	if err != nil {
+		return nil, wrapErr(err, "error in cognito.Adapter while sending GetGroupRequest")
+	}
+	return toGroupResult(output.Group), nil
+}
+
+// UpdateGroup changes only the settings given as options. Cognito resets settings missing in the request, so the group
+// is read first and its current settings are sent along, a concurrent update of other settings may be overwritten.
+func (ca *Adapter) UpdateGroup(groupName string, options ...func(*GroupConfig)) (*GroupResult, error) {
+
+	group, err := ca.GetGroup(groupName)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &GroupConfig{description: group.Description, precedence: group.Precedence, roleArn: group.RoleArn}
+	for _, opt := range options {
+		opt(cfg)
+	}
+
+	input := &cognitoidentityprovider.UpdateGroupInput{
+		Description: cfg.description,
+		GroupName:   &groupName,
+		Precedence:  cfg.precedence,
+		RoleArn:     cfg.roleArn,
+		UserPoolId:  &ca.poolID,
+	}
+
+	output, err := ca.provider.UpdateGroup(input)
+	if err != nil {
+		return nil, wrapErr(err, "error in cognito.Adapter while sending UpdateGroupRequest")
+	}
+	return toGroupResult(output.Group), nil
+}
+
+func (ca *Adapter) DeleteGroup(groupName string) error {
+
+	input := &cognitoidentityprovider.DeleteGroupInput{
+		GroupName:  &groupName,
+		UserPoolId: &ca.poolID,
+	}
+
+	_, err := ca.provider.DeleteGroup(input)
+	if err != nil {
+		return wrapErr(err, "error in cognito.Adapter while sending DeleteGroupRequest")
+	}
+	return nil
+}
+
+// ListGroups returns all groups of the pool, pages are fetched until the last one.
+func (ca *Adapter) ListGroups() ([]*GroupResult, error) {
+
+	input := &cognitoidentityprovider.ListGroupsInput{
+		UserPoolId: &ca.poolID,
+	}
+
+	groups := make([]*GroupResult, 0)
+	for {
+		output, err := ca.provider.ListGroups(input)
+		if err != nil {
+			return nil, wrapErr(err, "error in cognito.Adapter while sending ListGroupsRequest")
+		}
+
+		for _, group := range output.Groups {
+			groups = append(groups, toGroupResult(group))
+		}
+		if output.NextToken == nil || *output.NextToken == "" {
+			return groups, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
+
+func (ca *Adapter) AdminAddUserToGroup(username, groupName string) error {
+
+	input := &cognitoidentityprovider.AdminAddUserToGroupInput{
+		GroupName:  &groupName,
+		UserPoolId: &ca.poolID,
+		Username:   &username,
+	}
+
+	_, err := ca.provider.AdminAddUserToGroup(input)
+	if err != nil {
+		return wrapErr(err, "error in cognito.Adapter while sending AdminAddUserToGroupRequest")
+	}
+	return nil
+}
+
+func (ca *Adapter) AdminRemoveUserFromGroup(username, groupName string) error {
+
+	input := &cognitoidentityprovider.AdminRemoveUserFromGroupInput{
+		GroupName:  &groupName,
+		UserPoolId: &ca.poolID,
+		Username:   &username,
+	}
+
+	_, err := ca.provider.AdminRemoveUserFromGroup(input)
+	if err != nil {
+		return wrapErr(err, "error in cognito.Adapter while sending AdminRemoveUserFromGroupRequest")
+	}
+	return nil
+}
+
+// AdminListGroupsForUser returns all groups the user belongs to, pages are fetched until the last one.
+func (ca *Adapter) AdminListGroupsForUser(username string) ([]*GroupResult, error) {
+
+	input := &cognitoidentityprovider.AdminListGroupsForUserInput{
+		UserPoolId: &ca.poolID,
+		Username:   &username,
+	}
+
+	groups := make([]*GroupResult, 0)
+	for {
+		output, err := ca.provider.AdminListGroupsForUser(input)
+		if err != nil {
+			return nil, wrapErr(err, "error in cognito.Adapter while sending AdminListGroupsForUserRequest")
+		}
+
+		for _, group := range output.Groups {
+			groups = append(groups, toGroupResult(group))
+		}
+		if output.NextToken == nil || *output.NextToken == "" {
+			return groups, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
+
+// ListUsersInGroup returns all members of the group, pages are fetched until the last one.
+func (ca *Adapter) ListUsersInGroup(groupName string) ([]*UserResult, error) {
+
+	input := &cognitoidentityprovider.ListUsersInGroupInput{
+		GroupName:  &groupName,
+		UserPoolId: &ca.poolID,
+	}
+
+	users := make([]*UserResult, 0)
+	for {
+		output, err := ca.provider.ListUsersInGroup(input)
+		if err != nil {
+			return nil, wrapErr(err, "error in cognito.Adapter while sending ListUsersInGroupRequest")
+		}
+
+		for _, user := range output.Users {
+			users = append(users, toUserResult(user))
+		}
+		if output.NextToken == nil || *output.NextToken == "" {
+			return users, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
+
+func toGroupResult(group *cognitoidentityprovider.GroupType) *GroupResult {
+	if group == nil {
+		return nil
+	}
+
+	return &GroupResult{
+		GroupName:        group.GroupName,
+		Description:      group.Description,
+		Precedence:       group.Precedence,
+		RoleArn:          group.RoleArn,
+		CreationDate:     group.CreationDate,
+		LastModifiedDate: group.LastModifiedDate,
+	}
+}
@@ -0,0 +1,174 @@
+package cognito
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	cip "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var groupName = "admins"
+
+func TestAdapter_CreateGroup_ok(t *testing.T) {
+
+	// given
+	provider := &providerMock{}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	group, err := adapter.CreateGroup(groupName, GroupDescription("Administrators"), GroupPrecedence(1))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, groupName, aws.StringValue(group.GroupName))
+	assert.Equal(t, "Administrators", aws.StringValue(provider.createGroupInput.Description))
+	assert.Equal(t, int64(1), aws.Int64Value(provider.createGroupInput.Precedence))
+	assert.Nil(t, provider.createGroupInput.RoleArn)
+}
+
+func TestAdapter_CreateGroup_exists(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		createGroupErr: awsError(cip.ErrCodeGroupExistsException),
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	group, err := adapter.CreateGroup(groupName)
+
+	// then
+	assert.Nil(t, group)
+	assert.True(t, toAwsgoError(t, err).GroupExists())
+}
+
+func TestAdapter_UpdateGroup(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		getGroupOutput: &cip.GetGroupOutput{Group: &cip.GroupType{
+			GroupName:   aws.String(groupName),
+			Description: aws.String("Administrators"),
+			Precedence:  aws.Int64(1),
+		}},
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	_, err := adapter.UpdateGroup(groupName, GroupRoleArn("arn:aws:iam::123456789012:role/admins"))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "arn:aws:iam::123456789012:role/admins", aws.StringValue(provider.updateGroupInput.RoleArn))
+	assert.Equal(t, "Administrators", aws.StringValue(provider.updateGroupInput.Description))
+	assert.Equal(t, int64(1), aws.Int64Value(provider.updateGroupInput.Precedence))
+}
+
+func TestAdapter_UpdateGroup_notFound(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		getGroupErr: awsError(cip.ErrCodeResourceNotFoundException),
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	group, err := adapter.UpdateGroup(groupName, GroupPrecedence(2))
+
+	// then
+	assert.Nil(t, group)
+	assert.Equal(t, cip.ErrCodeResourceNotFoundException, toAwsgoError(t, err).Code)
+	assert.Nil(t, provider.updateGroupInput)
+}
+
+func TestAdapter_DeleteGroup_error(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		deleteGroupErr: errors.New("error while sending delete group request"),
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	err := adapter.DeleteGroup(groupName)
+
+	// then
+	assert.Error(t, err)
+}
+
+func TestAdapter_ListGroups_paginated(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		listGroupsOutputs: []*cip.ListGroupsOutput{
+			{Groups: []*cip.GroupType{{GroupName: aws.String("admins")}}, NextToken: aws.String("next-page")},
+			{Groups: []*cip.GroupType{{GroupName: aws.String("staff")}}},
+		},
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	groups, err := adapter.ListGroups()
+
+	// then
+	assert.NoError(t, err)
+	assert.Len(t, groups, 2)
+	assert.Equal(t, "staff", aws.StringValue(groups[1].GroupName))
+	assert.Equal(t, "next-page", aws.StringValue(provider.listGroupsInputs[1].NextToken))
+}
+
+func TestAdapter_AdminAddRemoveUserToGroup_error(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		addUserToGroupErr:      errors.New("error while sending add user to group request"),
+		removeUserFromGroupErr: errors.New("error while sending remove user from group request"),
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	addErr := adapter.AdminAddUserToGroup(username, groupName)
+	removeErr := adapter.AdminRemoveUserFromGroup(username, groupName)
+
+	// then
+	assert.Error(t, addErr)
+	assert.Error(t, removeErr)
+}
+
+func TestAdapter_AdminListGroupsForUser(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		groupsForUserOutput: &cip.AdminListGroupsForUserOutput{
+			Groups: []*cip.GroupType{{GroupName: &groupName}},
+		},
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	groups, err := adapter.AdminListGroupsForUser(username)
+
+	// then
+	assert.NoError(t, err)
+	assert.Len(t, groups, 1)
+}
+
+func TestAdapter_ListUsersInGroup(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		usersInGroupOutput: &cip.ListUsersInGroupOutput{
+			Users: []*cip.UserType{{Username: &username, Attributes: []*cip.AttributeType{attribute(emailAttr, email)}}},
+		},
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	users, err := adapter.ListUsersInGroup(groupName)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, email, users[0].Attributes[emailAttr])
+}
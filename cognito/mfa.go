@@ -0,0 +1,196 @@
+package cognito
+
+import (
+	"net/url"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/pkg/errors"
+)
+
+type SoftwareTokenResult struct {
+	SecretCode *string
+	// Session is returned when associating during MFA_SETUP challenge and has to be passed to verification
+	Session *string
+}
+
+// URI returns otpauth:// URI of the secret, usually rendered as QR code for authenticator apps.
+func (r *SoftwareTokenResult) URI(issuer, accountName string) string {
+	query := url.Values{}
+	query.Set("secret", aws.StringValue(r.SecretCode))
+	query.Set("issuer", issuer)
+
+	label := url.PathEscape(issuer + ":" + accountName)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+type VerifySoftwareTokenResult struct {
+	Status *string
+	// Session completes MFA_SETUP challenge when sent with MFASetupResponse
+	Session *string
+}
+
+// MFASetting enables MFA method and optionally makes it preferred one.
+type MFASetting struct {
+	Enabled   bool
+	Preferred bool
+}
+
+// MFAPreference holds settings of both methods, nil leaves the method unchanged.
+type MFAPreference struct {
+	SMS           *MFASetting
+	SoftwareToken *MFASetting
+}
+
+type MFASettingsResult struct {
+	// Preferred is SMS_MFA or SOFTWARE_TOKEN_MFA, nil when MFA is not set up
+	Preferred *string
+	Enabled   []*string
+}
+
+// AssociateSoftwareToken generates TOTP secret for the signed in user.
+func (ca *Adapter) AssociateSoftwareToken(accessToken string) (*SoftwareTokenResult, error) {
+	return ca.associateSoftwareToken(&cognitoidentityprovider.AssociateSoftwareTokenInput{
+		AccessToken: &accessToken,
+	})
+}
+
+// AssociateSoftwareTokenWithSession generates TOTP secret for the user answering MFA_SETUP challenge.
+func (ca *Adapter) AssociateSoftwareTokenWithSession(session *string) (*SoftwareTokenResult, error) {
+	return ca.associateSoftwareToken(&cognitoidentityprovider.AssociateSoftwareTokenInput{
+		Session: session,
+	})
+}
+
+// VerifySoftwareToken checks TOTP code of the associated secret and enables the software token for the user.
+func (ca *Adapter) VerifySoftwareToken(accessToken, code, deviceName string) (*VerifySoftwareTokenResult, error) {
+	return ca.verifySoftwareToken(&cognitoidentityprovider.VerifySoftwareTokenInput{
+		AccessToken: &accessToken,
+		UserCode:    &code,
+	}, deviceName)
+}
+
+// VerifySoftwareTokenWithSession checks TOTP code during MFA_SETUP challenge, the returned session completes the
+// challenge with RespondToChallenge and MFASetupResponse.
+func (ca *Adapter) VerifySoftwareTokenWithSession(session *string, code, deviceName string) (*VerifySoftwareTokenResult, error) {
+	return ca.verifySoftwareToken(&cognitoidentityprovider.VerifySoftwareTokenInput{
+		Session:  session,
+		UserCode: &code,
+	}, deviceName)
+}
+
+func (ca *Adapter) SetUserMFAPreference(accessToken string, preference MFAPreference) error {
+
+	input := &cognitoidentityprovider.SetUserMFAPreferenceInput{
+		AccessToken:              &accessToken,
+		SMSMfaSettings:           toSMSMfaSettings(preference.SMS),
+		SoftwareTokenMfaSettings: toSoftwareTokenMfaSettings(preference.SoftwareToken),
+	}
+
+	_, err := ca.provider.SetUserMFAPreference(input)
+	if err != nil {
+		return wrapErr(err, "error in cognito.Adapter while sending SetUserMFAPreferenceRequest")
+	}
+	return nil
+}
+
+func (ca *Adapter) AdminSetUserMFAPreference(username string, preference MFAPreference) error {
+
+	input := &cognitoidentityprovider.AdminSetUserMFAPreferenceInput{
+		SMSMfaSettings:           toSMSMfaSettings(preference.SMS),
+		SoftwareTokenMfaSettings: toSoftwareTokenMfaSettings(preference.SoftwareToken),
+		UserPoolId:               &ca.poolID,
+		Username:                 &username,
+	}
+
+	_, err := ca.provider.AdminSetUserMFAPreference(input)
+	if err != nil {
+		return wrapErr(err, "error in cognito.Adapter while sending AdminSetUserMFAPreferenceRequest")
+	}
+	return nil
+}
+
+// GetMFASettings reads MFA settings of the signed in user.
+func (ca *Adapter) GetMFASettings(accessToken string) (*MFASettingsResult, error) {
+
+	input := &cognitoidentityprovider.GetUserInput{
+		AccessToken: &accessToken,
+	}
+
+	output, err := ca.provider.GetUser(input)
+	if err != nil {
+		return nil, wrapErr(err, "error in cognito.Adapter while sending GetUserRequest")
+	}
+
+	return &MFASettingsResult{
+		Preferred: output.PreferredMfaSetting,
+		Enabled:   output.UserMFASettingList,
+	}, nil
+}
+
+func (ca *Adapter) AdminGetMFASettings(username string) (*MFASettingsResult, error) {
+
+	user, err := ca.AdminGetUser(username)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MFASettingsResult{
+		Preferred: user.PreferredMFASetting,
+		Enabled:   user.MFASettings,
+	}, nil
+}
+
+func (ca *Adapter) associateSoftwareToken(input *cognitoidentityprovider.AssociateSoftwareTokenInput) (*SoftwareTokenResult, error) {
+
+	output, err := ca.provider.AssociateSoftwareToken(input)
+	if err != nil {
+		return nil, wrapErr(err, "error in cognito.Adapter while sending AssociateSoftwareTokenRequest")
+	}
+
+	return &SoftwareTokenResult{
+		SecretCode: output.SecretCode,
+		Session:    output.Session,
+	}, nil
+}
+
+func (ca *Adapter) verifySoftwareToken(input *cognitoidentityprovider.VerifySoftwareTokenInput, deviceName string) (*VerifySoftwareTokenResult, error) {
+
+	if deviceName != "" {
+		input.FriendlyDeviceName = &deviceName
+	}
+
+	output, err := ca.provider.VerifySoftwareToken(input)
+	if err != nil {
+		return nil, wrapErr(err, "error in cognito.Adapter while sending VerifySoftwareTokenRequest")
+	}
+	if status := aws.StringValue(output.Status); status != cognitoidentityprovider.VerifySoftwareTokenResponseTypeSuccess {
+		return nil, wrapErrWithCode(errors.Errorf("verification status %s", status),
+			"error in cognito.Adapter while verifying software token", ErrCodeVerifySoftwareToken)
+	}
+
+	return &VerifySoftwareTokenResult{
+		Status:  output.Status,
+		Session: output.Session,
+	}, nil
+}
+
+func toSMSMfaSettings(setting *MFASetting) *cognitoidentityprovider.SMSMfaSettingsType {
+	if setting == nil {
+		return nil
+	}
+	return &cognitoidentityprovider.SMSMfaSettingsType{
+		Enabled:      aws.Bool(setting.Enabled),
+		PreferredMfa: aws.Bool(setting.Preferred),
+	}
+}
+
+func toSoftwareTokenMfaSettings(setting *MFASetting) *cognitoidentityprovider.SoftwareTokenMfaSettingsType {
+	if setting == nil {
+		return nil
+	}
+	return &cognitoidentityprovider.SoftwareTokenMfaSettingsType{
+		Enabled:      aws.Bool(setting.Enabled),
+		PreferredMfa: aws.Bool(setting.Preferred),
+	}
+}
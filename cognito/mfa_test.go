@@ -0,0 +1,157 @@
+package cognito
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	cip "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var accessToken = "access-token"
+
+func TestAdapter_AssociateSoftwareToken_ok(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		associateTokenOutput: &cip.AssociateSoftwareTokenOutput{SecretCode: aws.String("JBSWY3DPEHPK3PXP")},
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	result, err := adapter.AssociateSoftwareToken(accessToken)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, accessToken, aws.StringValue(provider.associateTokenInput.AccessToken))
+	assert.Equal(t, "otpauth://totp/Example%20Corp:john@example.com?issuer=Example+Corp&secret=JBSWY3DPEHPK3PXP",
+		result.URI("Example Corp", email))
+}
+
+func TestAdapter_AssociateSoftwareToken_error(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		associateTokenErr: errors.New("error while sending associate software token request"),
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	result, err := adapter.AssociateSoftwareTokenWithSession(&session)
+
+	// then
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, &session, provider.associateTokenInput.Session)
+}
+
+func TestAdapter_VerifySoftwareToken_ok(t *testing.T) {
+
+	// given
+	nextSession := "next-session-id"
+	provider := &providerMock{
+		verifyTokenOutput: &cip.VerifySoftwareTokenOutput{
+			Status:  aws.String(cip.VerifySoftwareTokenResponseTypeSuccess),
+			Session: &nextSession,
+		},
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	result, err := adapter.VerifySoftwareTokenWithSession(&session, "123456", "phone")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, &nextSession, result.Session)
+	assert.Equal(t, "phone", aws.StringValue(provider.verifyTokenInput.FriendlyDeviceName))
+}
+
+func TestAdapter_VerifySoftwareToken_notVerified(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		verifyTokenOutput: &cip.VerifySoftwareTokenOutput{
+			Status: aws.String(cip.VerifySoftwareTokenResponseTypeError),
+		},
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	result, err := adapter.VerifySoftwareToken(accessToken, "123456", "")
+
+	// then
+	assert.Nil(t, result)
+	assert.True(t, toAwsgoError(t, err).VerifySoftwareTokenFailed())
+	assert.Nil(t, provider.verifyTokenInput.FriendlyDeviceName)
+}
+
+func TestAdapter_SetUserMFAPreference(t *testing.T) {
+
+	// given
+	provider := &providerMock{}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	err := adapter.SetUserMFAPreference(accessToken, MFAPreference{
+		SoftwareToken: &MFASetting{Enabled: true, Preferred: true},
+	})
+
+	// then
+	assert.NoError(t, err)
+	assert.Nil(t, provider.setMFAPreferenceInput.SMSMfaSettings)
+	assert.True(t, aws.BoolValue(provider.setMFAPreferenceInput.SoftwareTokenMfaSettings.PreferredMfa))
+}
+
+func TestAdapter_AdminSetUserMFAPreference(t *testing.T) {
+
+	// given
+	provider := &providerMock{}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	err := adapter.AdminSetUserMFAPreference(username, MFAPreference{
+		SMS: &MFASetting{Enabled: false},
+	})
+
+	// then
+	assert.NoError(t, err)
+	assert.False(t, aws.BoolValue(provider.adminSetMFAPreferenceInput.SMSMfaSettings.Enabled))
+	assert.Nil(t, provider.adminSetMFAPreferenceInput.SoftwareTokenMfaSettings)
+}
+
+func TestAdapter_GetMFASettings(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		getUserOutput: &cip.GetUserOutput{
+			PreferredMfaSetting: aws.String(cip.ChallengeNameTypeSoftwareTokenMfa),
+			UserMFASettingList:  aws.StringSlice([]string{cip.ChallengeNameTypeSmsMfa, cip.ChallengeNameTypeSoftwareTokenMfa}),
+		},
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	settings, err := adapter.GetMFASettings(accessToken)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, cip.ChallengeNameTypeSoftwareTokenMfa, aws.StringValue(settings.Preferred))
+	assert.Len(t, settings.Enabled, 2)
+}
+
+func TestAdapter_AdminGetMFASettings_error(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		adminGetUserErr: awsError(cip.ErrCodeUserNotFoundException),
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	settings, err := adapter.AdminGetMFASettings(username)
+
+	// then
+	assert.Nil(t, settings)
+	assert.True(t, toAwsgoError(t, err).UserNotFound())
+}
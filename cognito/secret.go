@@ -0,0 +1,40 @@
+package cognito
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// ClientSecret sets secret of the app client, required to compute SECRET_HASH when the client has one.
+func ClientSecret(secret string) func(*Adapter) {
+	return func(ca *Adapter) {
+		ca.clientSecret = secret
+	}
+}
+
+// secretHash computes Base64(HMAC_SHA256(clientSecret, username + clientID)), nil when the client has no secret.
+func (ca *Adapter) secretHash(username string) (*string, error) {
+	if ca.clientSecret == "" {
+		return nil, nil
+	}
+
+	mac := hmac.New(sha256.New, []byte(ca.clientSecret))
+	if _, err := mac.Write([]byte(username + ca.clientID)); err != nil {
+		return nil, wrapErrWithCode(err, "Cannot encode secret hash", ErrSecretHashEncoding)
+	}
+	hash := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	return &hash, nil
+}
+
+// addSecretHash adds SECRET_HASH to auth parameters or challenge responses when the client has a secret.
+func (ca *Adapter) addSecretHash(params map[string]*string, username string) error {
+	secretHash, err := ca.secretHash(username)
+	if err != nil {
+		return err
+	}
+	if secretHash != nil {
+		params["SECRET_HASH"] = secretHash
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package cognito
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	cip "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	clientSecret       = "client-secret"
+	expectedSecretHash = "iNRMjm0AS6ryZlSDRwkYFTA+3dsjB9Y2jGhOA5vQiBM="
+)
+
+func TestAdapter_secretHash_noSecret(t *testing.T) {
+
+	// given
+	adapter := NewTestAdapter(&providerMock{})
+
+	// when
+	hash, err := adapter.secretHash(username)
+
+	// then
+	assert.NoError(t, err)
+	assert.Nil(t, hash)
+}
+
+func TestAdapter_SignIn_secretHash(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		authOutput: &cip.AdminInitiateAuthOutput{},
+	}
+	adapter := NewTestAdapter(provider)
+	ClientSecret(clientSecret)(adapter)
+
+	// when
+	_, err := adapter.SignIn(username, newPassword)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSecretHash, aws.StringValue(provider.authInput.AuthParameters["SECRET_HASH"]))
+}
+
+func TestAdapter_ChangePassword_secretHash(t *testing.T) {
+
+	// given
+	challengeName := cip.ChallengeNameTypeNewPasswordRequired
+	provider := &providerMock{
+		authOutput:                   &cip.AdminInitiateAuthOutput{ChallengeName: &challengeName, Session: &session},
+		respondToAuthChallengeOutput: &cip.AdminRespondToAuthChallengeOutput{},
+	}
+	adapter := NewTestAdapter(provider)
+	ClientSecret(clientSecret)(adapter)
+
+	// when
+	err := adapter.ChangePassword(username, oldPassword, newPassword)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSecretHash, aws.StringValue(provider.authInput.AuthParameters["SECRET_HASH"]))
+	assert.Equal(t, expectedSecretHash, aws.StringValue(provider.respondToAuthChallengeInput.ChallengeResponses["SECRET_HASH"]))
+}
+
+func TestAdapter_ConfirmForgotPassword_secretHash(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		forgetPassOutput: &cip.ConfirmForgotPasswordOutput{},
+	}
+	adapter := NewTestAdapter(provider)
+	ClientSecret(clientSecret)(adapter)
+
+	// when
+	err := adapter.ConfirmForgotPassword(username, newPassword, "23649")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSecretHash, aws.StringValue(provider.forgetPassInput.SecretHash))
+}
+
+func TestAdapter_RefreshTokens_secretHash(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		authOutput: &cip.AdminInitiateAuthOutput{AuthenticationResult: &cip.AuthenticationResultType{}},
+	}
+	adapter := NewTestAdapter(provider)
+	ClientSecret(clientSecret)(adapter)
+
+	// when
+	_, err := adapter.RefreshTokens(username, refreshToken)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSecretHash, aws.StringValue(provider.authInput.AuthParameters["SECRET_HASH"]))
+}
@@ -0,0 +1,118 @@
+package cognito
+
+import "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+
+type CodeDeliveryDetails struct {
+	AttributeName  *string
+	DeliveryMedium *string
+	Destination    *string
+}
+
+type SignUpResult struct {
+	CodeDeliveryDetails *CodeDeliveryDetails
+	UserConfirmed       *bool
+	UserSub             *string
+}
+
+// SignUp registers a user through the public client flow, validationData is passed to the pre sign-up trigger.
+func (ca *Adapter) SignUp(username, password string, attributesMap, validationData map[string]string) (*SignUpResult, error) {
+
+	secretHash, err := ca.secretHash(username)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &cognitoidentityprovider.SignUpInput{
+		ClientId:       &ca.clientID,
+		Password:       &password,
+		SecretHash:     secretHash,
+		UserAttributes: toAttributes(attributesMap),
+		Username:       &username,
+		ValidationData: toAttributes(validationData),
+	}
+
+	output, err := ca.provider.SignUp(input)
+	if err != nil {
+		return nil, wrapErr(err, "error in cognito.Adapter while sending SignUpRequest")
+	}
+
+	return &SignUpResult{
+		CodeDeliveryDetails: toCodeDeliveryDetails(output.CodeDeliveryDetails),
+		UserConfirmed:       output.UserConfirmed,
+		UserSub:             output.UserSub,
+	}, nil
+}
+
+func (ca *Adapter) ConfirmSignUp(username, confirmationCode string) error {
+
+	secretHash, err := ca.secretHash(username)
+	if err != nil {
+		return err
+	}
+
+	input := &cognitoidentityprovider.ConfirmSignUpInput{
+		ClientId:         &ca.clientID,
+		ConfirmationCode: &confirmationCode,
+		SecretHash:       secretHash,
+		Username:         &username,
+	}
+
+	_, err = ca.provider.ConfirmSignUp(input)
+	if err != nil {
+		return wrapErr(err, "error in cognito.Adapter while sending ConfirmSignUpRequest")
+	}
+	return nil
+}
+
+func (ca *Adapter) ResendConfirmationCode(username string) (*CodeDeliveryDetails, error) {
+
+	secretHash, err := ca.secretHash(username)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &cognitoidentityprovider.ResendConfirmationCodeInput{
+		ClientId:   &ca.clientID,
+		SecretHash: secretHash,
+		Username:   &username,
+	}
+
+	output, err := ca.provider.ResendConfirmationCode(input)
+	if err != nil {
+		return nil, wrapErr(err, "error in cognito.Adapter while sending ResendConfirmationCodeRequest")
+	}
+	return toCodeDeliveryDetails(output.CodeDeliveryDetails), nil
+}
+
+// ForgotPassword sends confirmation code required by ConfirmForgotPassword.
+func (ca *Adapter) ForgotPassword(username string) (*CodeDeliveryDetails, error) {
+
+	secretHash, err := ca.secretHash(username)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &cognitoidentityprovider.ForgotPasswordInput{
+		ClientId:   &ca.clientID,
+		SecretHash: secretHash,
+		Username:   &username,
+	}
+
+	output, err := ca.provider.ForgotPassword(input)
+	if err != nil {
+		return nil, wrapErr(err, "error in cognito.Adapter while sending ForgotPasswordRequest")
+	}
+	return toCodeDeliveryDetails(output.CodeDeliveryDetails), nil
+}
+
+func toCodeDeliveryDetails(details *cognitoidentityprovider.CodeDeliveryDetailsType) *CodeDeliveryDetails {
+	if details == nil {
+		return nil
+	}
+
+	return &CodeDeliveryDetails{
+		AttributeName:  details.AttributeName,
+		DeliveryMedium: details.DeliveryMedium,
+		Destination:    details.Destination,
+	}
+}
@@ -0,0 +1,118 @@
+package cognito
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	cip "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdapter_SignUp_ok(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		signUpOutput: &cip.SignUpOutput{
+			CodeDeliveryDetails: &cip.CodeDeliveryDetailsType{Destination: aws.String("j***@example.com")},
+			UserConfirmed:       aws.Bool(false),
+			UserSub:             aws.String("user-sub"),
+		},
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	result, err := adapter.SignUp(username, newPassword, map[string]string{emailAttr: email}, map[string]string{"source": "web"})
+
+	// then
+	assert.NoError(t, err)
+	assert.Nil(t, provider.signUpInput.SecretHash)
+	assert.Len(t, provider.signUpInput.ValidationData, 1)
+	assert.Equal(t, "user-sub", aws.StringValue(result.UserSub))
+	assert.Equal(t, "j***@example.com", aws.StringValue(result.CodeDeliveryDetails.Destination))
+}
+
+func TestAdapter_SignUp_secretHash(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		signUpOutput: &cip.SignUpOutput{},
+	}
+	adapter := NewTestAdapter(provider)
+	ClientSecret(clientSecret)(adapter)
+
+	// when
+	_, err := adapter.SignUp(username, newPassword, nil, nil)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSecretHash, aws.StringValue(provider.signUpInput.SecretHash))
+}
+
+func TestAdapter_SignUp_error(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		signUpErr: errors.New("error while sending sign up request"),
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	result, err := adapter.SignUp(username, newPassword, nil, nil)
+
+	// then
+	assert.Error(t, err)
+	assert.Nil(t, result)
+}
+
+func TestAdapter_ConfirmSignUp(t *testing.T) {
+
+	// given
+	provider := &providerMock{}
+	adapter := NewTestAdapter(provider)
+	ClientSecret(clientSecret)(adapter)
+
+	// when
+	err := adapter.ConfirmSignUp(username, "123456")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "123456", aws.StringValue(provider.confirmSignUpInput.ConfirmationCode))
+	assert.Equal(t, expectedSecretHash, aws.StringValue(provider.confirmSignUpInput.SecretHash))
+}
+
+func TestAdapter_ResendConfirmationCode_error(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		resendCodeErr: errors.New("error while sending resend confirmation code request"),
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	details, err := adapter.ResendConfirmationCode(username)
+
+	// then
+	assert.Error(t, err)
+	assert.Nil(t, details)
+}
+
+func TestAdapter_ForgotPassword_ok(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		forgotPassOutput: &cip.ForgotPasswordOutput{
+			CodeDeliveryDetails: &cip.CodeDeliveryDetailsType{DeliveryMedium: aws.String(cip.DeliveryMediumTypeEmail)},
+		},
+	}
+	adapter := NewTestAdapter(provider)
+	ClientSecret(clientSecret)(adapter)
+
+	// when
+	details, err := adapter.ForgotPassword(username)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, expectedSecretHash, aws.StringValue(provider.forgotPassInput.SecretHash))
+	assert.Equal(t, cip.DeliveryMediumTypeEmail, aws.StringValue(details.DeliveryMedium))
+}
@@ -0,0 +1,156 @@
+package cognito
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/pkg/errors"
+)
+
+const defaultRefreshMargin = 5 * time.Minute
+
+// RefreshTokens issues new access and id tokens, Cognito does not rotate the refresh token so the given one is
+// returned in the result. Username is only used for SECRET_HASH, for pools with aliases it has to be the user's sub.
+func (ca *Adapter) RefreshTokens(username, refreshToken string) (*AuthenticationResult, error) {
+
+	authFlow := cognitoidentityprovider.AuthFlowTypeRefreshTokenAuth
+
+	input := &cognitoidentityprovider.AdminInitiateAuthInput{
+		AuthFlow: &authFlow,
+		AuthParameters: map[string]*string{
+			"REFRESH_TOKEN": &refreshToken,
+		},
+		ClientId:   &ca.clientID,
+		UserPoolId: &ca.poolID,
+	}
+	if err := ca.addSecretHash(input.AuthParameters, username); err != nil {
+		return nil, err
+	}
+
+	output, err := ca.provider.AdminInitiateAuth(input)
+	if err != nil {
+		return nil, wrapErr(err, "error in cognito.Adapter while sending AdminInitiateAuthRequest")
+	}
+	if output.AuthenticationResult == nil {
+		return nil, wrapErrWithCode(errors.Errorf("unexpected challenge %s", aws.StringValue(output.ChallengeName)),
+			"error in cognito.Adapter while refreshing tokens", ErrCodeRefreshTokens)
+	}
+
+	result := toAuthenticationResult(output.AuthenticationResult)
+	if result.RefreshToken == nil {
+		result.RefreshToken = &refreshToken
+	}
+	return result, nil
+}
+
+// RevokeTokens signs the user out of all devices, invalidating every refresh token issued to them.
+func (ca *Adapter) RevokeTokens(accessToken string) error {
+
+	input := &cognitoidentityprovider.GlobalSignOutInput{
+		AccessToken: &accessToken,
+	}
+
+	_, err := ca.provider.GlobalSignOut(input)
+	if err != nil {
+		return wrapErr(err, "error in cognito.Adapter while sending GlobalSignOutRequest")
+	}
+	return nil
+}
+
+type TokenConfig struct {
+	refreshMargin time.Duration
+}
+
+// RefreshMargin sets how long before expiry the tokens are refreshed, 5 minutes by default.
+func RefreshMargin(margin time.Duration) func(*TokenConfig) {
+	return func(c *TokenConfig) {
+		c.refreshMargin = margin
+	}
+}
+
+// TokenManager caches tokens of a signed in user and refreshes them before they expire. It is safe for concurrent
+// use, concurrent callers wait for a single refresh.
+type TokenManager struct {
+	adapter   *Adapter
+	username  string
+	cfg       *TokenConfig
+	now       func() time.Time
+	mu        sync.Mutex
+	tokens    *AuthenticationResult
+	expiresAt time.Time
+}
+
+// NewTokenManager manages tokens issued by SignIn or RespondToChallenge, they have to include the refresh token.
+func (ca *Adapter) NewTokenManager(username string, tokens *AuthenticationResult,
+	options ...func(*TokenConfig)) (*TokenManager, error) {
+
+	if tokens == nil || tokens.RefreshToken == nil {
+		return nil, wrapErrWithCode(errors.New("no refresh token"),
+			"error in cognito.Adapter while creating token manager", ErrCodeRefreshTokens)
+	}
+
+	cfg := &TokenConfig{refreshMargin: defaultRefreshMargin}
+	for _, opt := range options {
+		opt(cfg)
+	}
+
+	m := &TokenManager{adapter: ca, username: username, cfg: cfg, now: time.Now}
+	m.set(tokens)
+	return m, nil
+}
+
+// Tokens returns cached tokens, refreshed first when they expire within the refresh margin.
+func (m *TokenManager) Tokens() (*AuthenticationResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.now().Add(m.cfg.refreshMargin).Before(m.expiresAt) {
+		return m.tokens, nil
+	}
+	if m.tokens.RefreshToken == nil {
+		return nil, wrapErrWithCode(errors.New("no refresh token"),
+			"error in cognito.TokenManager while refreshing tokens", ErrCodeRefreshTokens)
+	}
+
+	tokens, err := m.adapter.RefreshTokens(m.username, *m.tokens.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+	m.set(tokens)
+	return m.tokens, nil
+}
+
+func (m *TokenManager) AccessToken() (string, error) {
+	tokens, err := m.Tokens()
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(tokens.AccessToken), nil
+}
+
+func (m *TokenManager) IDToken() (string, error) {
+	tokens, err := m.Tokens()
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(tokens.IDToken), nil
+}
+
+// Revoke invalidates the refresh token with RevokeTokens, the manager cannot be used afterwards.
+func (m *TokenManager) Revoke() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.adapter.RevokeTokens(aws.StringValue(m.tokens.AccessToken)); err != nil {
+		return err
+	}
+	m.set(&AuthenticationResult{})
+	return nil
+}
+
+func (m *TokenManager) set(tokens *AuthenticationResult) {
+	m.tokens = tokens
+	m.expiresAt = m.now().Add(time.Duration(aws.Int64Value(tokens.ExpiresIn)) * time.Second)
+}
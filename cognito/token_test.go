@@ -0,0 +1,155 @@
+package cognito
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	cip "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var refreshToken = "refresh-token"
+
+func TestAdapter_RefreshTokens_ok(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		authOutput: &cip.AdminInitiateAuthOutput{
+			AuthenticationResult: &cip.AuthenticationResultType{AccessToken: aws.String("new-access-token")},
+		},
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	result, err := adapter.RefreshTokens(username, refreshToken)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, cip.AuthFlowTypeRefreshTokenAuth, aws.StringValue(provider.authInput.AuthFlow))
+	assert.Equal(t, refreshToken, aws.StringValue(provider.authInput.AuthParameters["REFRESH_TOKEN"]))
+	assert.Equal(t, "new-access-token", aws.StringValue(result.AccessToken))
+	assert.Equal(t, refreshToken, aws.StringValue(result.RefreshToken))
+}
+
+func TestAdapter_RefreshTokens_error(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		authErr: errors.New("error while sending auth request"),
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	result, err := adapter.RefreshTokens(username, refreshToken)
+
+	// then
+	assert.Error(t, err)
+	assert.Nil(t, result)
+}
+
+func TestAdapter_RevokeTokens(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		globalSignOutErr: errors.New("error while sending global sign out request"),
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	err := adapter.RevokeTokens("access-token")
+
+	// then
+	assert.Error(t, err)
+}
+
+func TestTokenManager_cached(t *testing.T) {
+
+	// given
+	provider := &providerMock{}
+	manager, _ := NewTestAdapter(provider).NewTokenManager(username, &AuthenticationResult{
+		AccessToken:  aws.String("access-token"),
+		ExpiresIn:    aws.Int64(3600),
+		RefreshToken: &refreshToken,
+	})
+
+	// when
+	token, err := manager.AccessToken()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "access-token", token)
+	assert.Equal(t, 0, provider.authCalls)
+}
+
+func TestTokenManager_refreshedOnce(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		authOutput: &cip.AdminInitiateAuthOutput{
+			AuthenticationResult: &cip.AuthenticationResultType{
+				AccessToken: aws.String("new-access-token"),
+				ExpiresIn:   aws.Int64(3600),
+			},
+		},
+	}
+	manager, _ := NewTestAdapter(provider).NewTokenManager(username, &AuthenticationResult{
+		AccessToken:  aws.String("access-token"),
+		ExpiresIn:    aws.Int64(60),
+		RefreshToken: &refreshToken,
+	}, RefreshMargin(2*time.Minute))
+
+	// when
+	var wg sync.WaitGroup
+	tokens := make([]string, 10)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tokens[i], _ = manager.AccessToken()
+		}(i)
+	}
+	wg.Wait()
+
+	// then
+	assert.Equal(t, 1, provider.authCalls)
+	for _, token := range tokens {
+		assert.Equal(t, "new-access-token", token)
+	}
+}
+
+func TestNewTokenManager_noRefreshToken(t *testing.T) {
+
+	// given
+	adapter := NewTestAdapter(&providerMock{})
+
+	// when
+	manager, err := adapter.NewTokenManager(username, &AuthenticationResult{AccessToken: aws.String("access-token")})
+	_, nilTokensErr := adapter.NewTokenManager(username, nil)
+
+	// then
+	assert.Nil(t, manager)
+	assert.Equal(t, ErrCodeRefreshTokens, toAwsgoError(t, err).Code)
+	assert.Equal(t, ErrCodeRefreshTokens, toAwsgoError(t, nilTokensErr).Code)
+}
+
+func TestTokenManager_revoked(t *testing.T) {
+
+	// given
+	provider := &providerMock{globalSignOutOutput: &cip.GlobalSignOutOutput{}}
+	manager, _ := NewTestAdapter(provider).NewTokenManager(username, &AuthenticationResult{
+		AccessToken:  aws.String("access-token"),
+		ExpiresIn:    aws.Int64(3600),
+		RefreshToken: &refreshToken,
+	})
+
+	// when
+	revokeErr := manager.Revoke()
+	_, err := manager.Tokens()
+
+	// then
+	assert.NoError(t, revokeErr)
+	assert.Equal(t, ErrCodeRefreshTokens, toAwsgoError(t, err).Code)
+}
@@ -0,0 +1,208 @@
+package cognito
+
+import (
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+)
+
+type UserResult struct {
+	Attributes       map[string]string
+	Enabled          *bool
+	CreateDate       *time.Time
+	LastModifiedDate *time.Time
+	UserStatus       *string
+	Username         *string
+	// PreferredMFASetting and MFASettings are only returned by AdminGetUser
+	PreferredMFASetting *string
+	MFASettings         []*string
+}
+
+// FilterEquals builds ListUsers filter matching users whose attribute equals value.
+func FilterEquals(attribute, value string) string {
+	return filter(attribute, "=", value)
+}
+
+// FilterStartsWith builds ListUsers filter matching users whose attribute starts with prefix.
+func FilterStartsWith(attribute, prefix string) string {
+	return filter(attribute, "^=", prefix)
+}
+
+// ListUsersAttributes limits attributes returned for each user, all are returned by default.
+func ListUsersAttributes(names ...string) func(*cognitoidentityprovider.ListUsersInput) {
+	return func(in *cognitoidentityprovider.ListUsersInput) {
+		for _, name := range names {
+			name := name
+			in.AttributesToGet = append(in.AttributesToGet, &name)
+		}
+	}
+}
+
+// ListUsers returns all users matching the filter, an empty filter matches all users. Pages are fetched until the
+// last one.
+func (ca *Adapter) ListUsers(filter string, options ...func(*cognitoidentityprovider.ListUsersInput)) ([]*UserResult, error) {
+
+	input := &cognitoidentityprovider.ListUsersInput{
+		UserPoolId: &ca.poolID,
+	}
+	if filter != "" {
+		input.Filter = &filter
+	}
+	for _, opt := range options {
+		opt(input)
+	}
+
+	users := make([]*UserResult, 0)
+	for {
+		output, err := ca.provider.ListUsers(input)
+		if err != nil {
+			return nil, wrapErr(err, "error in cognito.Adapter while sending ListUsersRequest")
+		}
+
+		for _, user := range output.Users {
+			users = append(users, toUserResult(user))
+		}
+		if output.PaginationToken == nil || *output.PaginationToken == "" {
+			return users, nil
+		}
+		input.PaginationToken = output.PaginationToken
+	}
+}
+
+func (ca *Adapter) AdminGetUser(username string) (*UserResult, error) {
+
+	input := &cognitoidentityprovider.AdminGetUserInput{
+		UserPoolId: &ca.poolID,
+		Username:   &username,
+	}
+
+	output, err := ca.provider.AdminGetUser(input)
+	if err != nil {
+		return nil, wrapErr(err, "error in cognito.Adapter while sending AdminGetUserRequest")
+	}
+
+	return &UserResult{
+		Attributes:          fromAttributes(output.UserAttributes),
+		Enabled:             output.Enabled,
+		CreateDate:          output.UserCreateDate,
+		LastModifiedDate:    output.UserLastModifiedDate,
+		UserStatus:          output.UserStatus,
+		Username:            output.Username,
+		PreferredMFASetting: output.PreferredMfaSetting,
+		MFASettings:         output.UserMFASettingList,
+	}, nil
+}
+
+func (ca *Adapter) AdminUpdateUserAttributes(username string, attributesMap map[string]string) error {
+
+	input := &cognitoidentityprovider.AdminUpdateUserAttributesInput{
+		UserAttributes: toAttributes(attributesMap),
+		UserPoolId:     &ca.poolID,
+		Username:       &username,
+	}
+
+	_, err := ca.provider.AdminUpdateUserAttributes(input)
+	if err != nil {
+		return wrapErr(err, "error in cognito.Adapter while sending AdminUpdateUserAttributesRequest")
+	}
+	return nil
+}
+
+func (ca *Adapter) AdminDeleteUserAttributes(username string, attributeNames ...string) error {
+
+	names := make([]*string, 0, len(attributeNames))
+	for _, name := range attributeNames {
+		name := name
+		names = append(names, &name)
+	}
+
+	input := &cognitoidentityprovider.AdminDeleteUserAttributesInput{
+		UserAttributeNames: names,
+		UserPoolId:         &ca.poolID,
+		Username:           &username,
+	}
+
+	_, err := ca.provider.AdminDeleteUserAttributes(input)
+	if err != nil {
+		return wrapErr(err, "error in cognito.Adapter while sending AdminDeleteUserAttributesRequest")
+	}
+	return nil
+}
+
+func (ca *Adapter) AdminDisableUser(username string) error {
+
+	input := &cognitoidentityprovider.AdminDisableUserInput{
+		UserPoolId: &ca.poolID,
+		Username:   &username,
+	}
+
+	_, err := ca.provider.AdminDisableUser(input)
+	if err != nil {
+		return wrapErr(err, "error in cognito.Adapter while sending AdminDisableUserRequest")
+	}
+	return nil
+}
+
+func (ca *Adapter) AdminEnableUser(username string) error {
+
+	input := &cognitoidentityprovider.AdminEnableUserInput{
+		UserPoolId: &ca.poolID,
+		Username:   &username,
+	}
+
+	_, err := ca.provider.AdminEnableUser(input)
+	if err != nil {
+		return wrapErr(err, "error in cognito.Adapter while sending AdminEnableUserRequest")
+	}
+	return nil
+}
+
+func (ca *Adapter) AdminDeleteUser(username string) error {
+
+	input := &cognitoidentityprovider.AdminDeleteUserInput{
+		UserPoolId: &ca.poolID,
+		Username:   &username,
+	}
+
+	_, err := ca.provider.AdminDeleteUser(input)
+	if err != nil {
+		return wrapErr(err, "error in cognito.Adapter while sending AdminDeleteUserRequest")
+	}
+	return nil
+}
+
+// AdminSetUserPassword sets permanent password, the user does not have to change it on the next sign in.
+func (ca *Adapter) AdminSetUserPassword(username, password string) error {
+
+	permanent := true
+
+	input := &cognitoidentityprovider.AdminSetUserPasswordInput{
+		Password:   &password,
+		Permanent:  &permanent,
+		UserPoolId: &ca.poolID,
+		Username:   &username,
+	}
+
+	_, err := ca.provider.AdminSetUserPassword(input)
+	if err != nil {
+		return wrapErr(err, "error in cognito.Adapter while sending AdminSetUserPasswordRequest")
+	}
+	return nil
+}
+
+func toUserResult(user *cognitoidentityprovider.UserType) *UserResult {
+	return &UserResult{
+		Attributes:       fromAttributes(user.Attributes),
+		Enabled:          user.Enabled,
+		CreateDate:       user.UserCreateDate,
+		LastModifiedDate: user.UserLastModifiedDate,
+		UserStatus:       user.UserStatus,
+		Username:         user.Username,
+	}
+}
+
+func filter(attribute, operator, value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+	return attribute + ` ` + operator + ` "` + value + `"`
+}
@@ -0,0 +1,167 @@
+package cognito
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	cip "github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdapter_ListUsers_paginated(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		listUsersOutputs: []*cip.ListUsersOutput{
+			{
+				Users:           []*cip.UserType{{Username: aws.String("john"), Attributes: []*cip.AttributeType{attribute(emailAttr, email)}}},
+				PaginationToken: aws.String("next-page"),
+			},
+			{
+				Users: []*cip.UserType{{Username: aws.String("jane")}},
+			},
+		},
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	users, err := adapter.ListUsers(FilterStartsWith(emailAttr, "j"), ListUsersAttributes(emailAttr))
+
+	// then
+	assert.NoError(t, err)
+	assert.Len(t, users, 2)
+	assert.Equal(t, email, users[0].Attributes[emailAttr])
+	assert.Equal(t, "jane", aws.StringValue(users[1].Username))
+	assert.Equal(t, `email ^= "j"`, aws.StringValue(provider.listUsersInputs[0].Filter))
+	assert.Nil(t, provider.listUsersInputs[0].PaginationToken)
+	assert.Equal(t, "next-page", aws.StringValue(provider.listUsersInputs[1].PaginationToken))
+	assert.Len(t, provider.listUsersInputs[1].AttributesToGet, 1)
+}
+
+func TestAdapter_ListUsers_error(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		listUsersErr: errors.New("error while sending list users request"),
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	users, err := adapter.ListUsers("")
+
+	// then
+	assert.Error(t, err)
+	assert.Nil(t, users)
+	assert.Nil(t, provider.listUsersInputs[0].Filter)
+}
+
+func TestFilterEquals_escaped(t *testing.T) {
+
+	// when
+	filter := FilterEquals("name", `John "Johnny" Doe`)
+
+	// then
+	assert.Equal(t, `name = "John \"Johnny\" Doe"`, filter)
+}
+
+func TestAdapter_AdminGetUser_ok(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		adminGetUserOutput: &cip.AdminGetUserOutput{
+			Username:            &username,
+			UserAttributes:      []*cip.AttributeType{attribute(emailAttr, email)},
+			Enabled:             aws.Bool(true),
+			PreferredMfaSetting: aws.String(cip.ChallengeNameTypeSoftwareTokenMfa),
+		},
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	user, err := adapter.AdminGetUser(username)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, email, user.Attributes[emailAttr])
+	assert.Equal(t, cip.ChallengeNameTypeSoftwareTokenMfa, aws.StringValue(user.PreferredMFASetting))
+}
+
+func TestAdapter_AdminGetUser_error(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		adminGetUserErr: errors.New("error while sending admin get user request"),
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	user, err := adapter.AdminGetUser(username)
+
+	// then
+	assert.Error(t, err)
+	assert.Nil(t, user)
+}
+
+func TestAdapter_AdminUpdateUserAttributes(t *testing.T) {
+
+	// given
+	provider := &providerMock{}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	err := adapter.AdminUpdateUserAttributes(username, map[string]string{emailAttr: email})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{emailAttr: email}, fromAttributes(provider.updateAttributesInput.UserAttributes))
+}
+
+func TestAdapter_AdminDeleteUserAttributes(t *testing.T) {
+
+	// given
+	provider := &providerMock{}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	err := adapter.AdminDeleteUserAttributes(username, emailAttr, "phone_number")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []string{emailAttr, "phone_number"}, aws.StringValueSlice(provider.deleteAttributesInput.UserAttributeNames))
+}
+
+func TestAdapter_AdminDisableEnableDeleteUser_error(t *testing.T) {
+
+	// given
+	provider := &providerMock{
+		disableUserErr: errors.New("error while sending admin disable user request"),
+		enableUserErr:  errors.New("error while sending admin enable user request"),
+		deleteUserErr:  errors.New("error while sending admin delete user request"),
+	}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	disableErr := adapter.AdminDisableUser(username)
+	enableErr := adapter.AdminEnableUser(username)
+	deleteErr := adapter.AdminDeleteUser(username)
+
+	// then
+	assert.Error(t, disableErr)
+	assert.Error(t, enableErr)
+	assert.Error(t, deleteErr)
+}
+
+func TestAdapter_AdminSetUserPassword(t *testing.T) {
+
+	// given
+	provider := &providerMock{}
+	adapter := NewTestAdapter(provider)
+
+	// when
+	err := adapter.AdminSetUserPassword(username, newPassword)
+
+	// then
+	assert.NoError(t, err)
+	assert.True(t, aws.BoolValue(provider.setPasswordInput.Permanent))
+}
@@ -0,0 +1,321 @@
+package cognito
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	TokenUseAccess = "access"
+	TokenUseID     = "id"
+
+	defaultClockSkew       = time.Minute
+	defaultJWKSRefreshRate = time.Minute
+	defaultJWKSTimeout     = 10 * time.Second
+)
+
+type JWK struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKSSource provides signing keys of the user pool, implement it to verify tokens without network access.
+type JWKSSource interface {
+	JWKS() (*JWKS, error)
+}
+
+type httpJWKSSource struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPJWKSSource fetches keys from the given URL, usually <issuer>/.well-known/jwks.json. Nil client is replaced by
+// one with 10 seconds timeout.
+func NewHTTPJWKSSource(url string, client *http.Client) JWKSSource {
+	if client == nil {
+		client = &http.Client{Timeout: defaultJWKSTimeout}
+	}
+	return &httpJWKSSource{url: url, client: client}
+}
+
+func (s *httpJWKSSource) JWKS() (*JWKS, error) {
+	resp, err := s.client.Get(s.url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status %s", resp.Status)
+	}
+	jwks := &JWKS{}
+	if err := json.NewDecoder(resp.Body).Decode(jwks); err != nil {
+		return nil, err
+	}
+	return jwks, nil
+}
+
+// Claims holds verified claims of access and id tokens, Username is read from username or cognito:username claim and
+// ClientID from client_id or aud claim respectively.
+type Claims struct {
+	Subject   string
+	Username  string
+	TokenUse  string
+	ClientID  string
+	Issuer    string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	AuthTime  time.Time
+	Groups    []string
+	Scopes    []string
+	Email     string
+	Raw       map[string]interface{}
+}
+
+type rawClaims struct {
+	Sub             string   `json:"sub"`
+	Username        string   `json:"username"`
+	CognitoUsername string   `json:"cognito:username"`
+	TokenUse        string   `json:"token_use"`
+	ClientID        string   `json:"client_id"`
+	Aud             string   `json:"aud"`
+	Iss             string   `json:"iss"`
+	Iat             int64    `json:"iat"`
+	Exp             int64    `json:"exp"`
+	AuthTime        int64    `json:"auth_time"`
+	Groups          []string `json:"cognito:groups"`
+	Scope           string   `json:"scope"`
+	Email           string   `json:"email"`
+}
+
+type VerifierConfig struct {
+	source    JWKSSource
+	clockSkew time.Duration
+}
+
+// VerifierJWKSSource replaces the default source which fetches keys from the user pool.
+func VerifierJWKSSource(source JWKSSource) func(*VerifierConfig) {
+	return func(c *VerifierConfig) {
+		c.source = source
+	}
+}
+
+// ClockSkew sets tolerance for expiry and issue time checks, 1 minute by default.
+func ClockSkew(skew time.Duration) func(*VerifierConfig) {
+	return func(c *VerifierConfig) {
+		c.clockSkew = skew
+	}
+}
+
+// Verifier validates tokens issued by the user pool locally. Keys are fetched on first use and fetched again when a
+// token is signed with an unknown key, at most once a minute whether the fetch succeeded or not. It is safe for
+// concurrent use, concurrent callers wait for a single fetch.
+type Verifier struct {
+	issuer    string
+	clientID  string
+	cfg       *VerifierConfig
+	now       func() time.Time
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+	fetchErr  error
+	fetching  chan struct{}
+}
+
+// NewVerifier creates verifier for tokens of the given app client, the region is read from the pool id.
+func NewVerifier(poolID, clientID string, options ...func(*VerifierConfig)) *Verifier {
+	region := strings.SplitN(poolID, "_", 2)[0]
+	issuer := "https://cognito-idp." + region + ".amazonaws.com/" + poolID
+
+	cfg := &VerifierConfig{clockSkew: defaultClockSkew}
+	for _, opt := range options {
+		opt(cfg)
+	}
+	if cfg.source == nil {
+		cfg.source = NewHTTPJWKSSource(issuer+"/.well-known/jwks.json", nil)
+	}
+
+	return &Verifier{issuer: issuer, clientID: clientID, cfg: cfg, now: time.Now}
+}
+
+func (v *Verifier) VerifyAccessToken(token string) (*Claims, error) {
+	return v.verify(token, TokenUseAccess)
+}
+
+func (v *Verifier) VerifyIDToken(token string) (*Claims, error) {
+	return v.verify(token, TokenUseID)
+}
+
+func (v *Verifier) verify(token, tokenUse string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, invalidToken("malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, invalidToken("malformed header")
+	}
+	if header.Alg != "RS256" {
+		return nil, invalidToken("unsupported algorithm " + header.Alg)
+	}
+
+	key, err := v.key(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, invalidToken("malformed signature")
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, wrapErrWithCode(err, "error in cognito.Verifier while verifying token", ErrCodeInvalidToken)
+	}
+
+	raw := rawClaims{}
+	claims := &Claims{}
+	if err := decodeSegment(parts[1], &raw); err != nil {
+		return nil, invalidToken("malformed claims")
+	}
+	if err := decodeSegment(parts[1], &claims.Raw); err != nil {
+		return nil, invalidToken("malformed claims")
+	}
+
+	if err := v.check(&raw, claims, tokenUse); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (v *Verifier) check(raw *rawClaims, claims *Claims, tokenUse string) error {
+	claims.Subject = raw.Sub
+	claims.Username = raw.Username
+	claims.ClientID = raw.ClientID
+	if tokenUse == TokenUseID {
+		claims.Username = raw.CognitoUsername
+		claims.ClientID = raw.Aud
+	}
+	claims.TokenUse = raw.TokenUse
+	claims.Issuer = raw.Iss
+	claims.IssuedAt = time.Unix(raw.Iat, 0)
+	claims.ExpiresAt = time.Unix(raw.Exp, 0)
+	claims.AuthTime = time.Unix(raw.AuthTime, 0)
+	claims.Groups = raw.Groups
+	claims.Scopes = strings.Fields(raw.Scope)
+	claims.Email = raw.Email
+
+	switch now := v.now(); {
+	case claims.Issuer != v.issuer:
+		return invalidToken("unexpected issuer " + claims.Issuer)
+	case claims.TokenUse != tokenUse:
+		return invalidToken("unexpected token use " + claims.TokenUse)
+	case claims.ClientID != v.clientID:
+		return invalidToken("unexpected client id " + claims.ClientID)
+	case claims.IssuedAt.After(now.Add(v.cfg.clockSkew)):
+		return invalidToken("token issued in the future")
+	case !now.Add(-v.cfg.clockSkew).Before(claims.ExpiresAt):
+		return wrapErrWithCode(errors.Errorf("token expired at %s", claims.ExpiresAt),
+			"error in cognito.Verifier while verifying token", ErrCodeTokenExpired)
+	}
+	return nil
+}
+
+func (v *Verifier) key(kid string) (*rsa.PublicKey, error) {
+	for {
+		v.mu.Lock()
+		if key, ok := v.keys[kid]; ok {
+			v.mu.Unlock()
+			return key, nil
+		}
+		if fetching := v.fetching; fetching != nil {
+			v.mu.Unlock()
+			<-fetching
+			continue
+		}
+		if !v.fetchedAt.IsZero() && v.now().Sub(v.fetchedAt) < defaultJWKSRefreshRate {
+			err := v.fetchErr
+			v.mu.Unlock()
+			if err != nil {
+				return nil, err
+			}
+			return nil, invalidToken("unknown key " + kid)
+		}
+		fetching := make(chan struct{})
+		v.fetching = fetching
+		v.mu.Unlock()
+
+		keys, err := v.fetchKeys()
+
+		v.mu.Lock()
+		if err == nil {
+			v.keys = keys
+		}
+		v.fetchErr, v.fetchedAt, v.fetching = err, v.now(), nil
+		v.mu.Unlock()
+		close(fetching)
+	}
+}
+
+// fetchKeys is called without holding the lock, keys of the last successful fetch are kept when it fails.
+func (v *Verifier) fetchKeys() (map[string]*rsa.PublicKey, error) {
+	jwks, err := v.cfg.source.JWKS()
+	if err != nil {
+		return nil, wrapErrWithCode(err, "error in cognito.Verifier while fetching JWKS", ErrCodeJWKSFetch)
+	}
+	keys := make(map[string]*rsa.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if key, err := jwk.publicKey(); err == nil {
+			keys[jwk.Kid] = key
+		}
+	}
+	return keys, nil
+}
+
+func (k JWK) publicKey() (*rsa.PublicKey, error) {
+	if k.Kty != "RSA" {
+		return nil, errors.Errorf("unsupported key type %s", k.Kty)
+	}
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, err
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func invalidToken(reason string) error {
+	return wrapErrWithCode(errors.New(reason), "error in cognito.Verifier while verifying token", ErrCodeInvalidToken)
+}
@@ -0,0 +1,278 @@
+package cognito
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	verifierPoolID = "eu-west-1_abcdef"
+	verifierIssuer = "https://cognito-idp.eu-west-1.amazonaws.com/eu-west-1_abcdef"
+	verifierKid    = "key-1"
+)
+
+type staticJWKSSource struct {
+	jwks  *JWKS
+	calls int
+}
+
+func (s *staticJWKSSource) JWKS() (*JWKS, error) {
+	s.calls++
+	return s.jwks, nil
+}
+
+// blockingJWKSSource returns keys once released, or err when set.
+type blockingJWKSSource struct {
+	jwks    *JWKS
+	err     error
+	release chan struct{}
+	calls   int32
+}
+
+func (s *blockingJWKSSource) JWKS() (*JWKS, error) {
+	atomic.AddInt32(&s.calls, 1)
+	<-s.release
+	return s.jwks, s.err
+}
+
+func TestVerifier_VerifyAccessToken_ok(t *testing.T) {
+
+	// given
+	key := generateKey(t)
+	verifier := newTestVerifier(key)
+	token := signToken(t, key, verifierKid, map[string]interface{}{
+		"sub":            "user-sub",
+		"username":       username,
+		"token_use":      TokenUseAccess,
+		"client_id":      clientID,
+		"iss":            verifierIssuer,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"cognito:groups": []string{"admins", "staff"},
+		"scope":          "aws.cognito.signin.user.admin openid",
+	})
+
+	// when
+	claims, err := verifier.VerifyAccessToken(token)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "user-sub", claims.Subject)
+	assert.Equal(t, username, claims.Username)
+	assert.Equal(t, []string{"admins", "staff"}, claims.Groups)
+	assert.Equal(t, []string{"aws.cognito.signin.user.admin", "openid"}, claims.Scopes)
+}
+
+func TestVerifier_VerifyIDToken_ok(t *testing.T) {
+
+	// given
+	key := generateKey(t)
+	verifier := newTestVerifier(key)
+	token := signToken(t, key, verifierKid, map[string]interface{}{
+		"sub":              "user-sub",
+		"cognito:username": username,
+		"email":            email,
+		"token_use":        TokenUseID,
+		"aud":              clientID,
+		"iss":              verifierIssuer,
+		"iat":              time.Now().Unix(),
+		"exp":              time.Now().Add(time.Hour).Unix(),
+	})
+
+	// when
+	claims, err := verifier.VerifyIDToken(token)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, username, claims.Username)
+	assert.Equal(t, clientID, claims.ClientID)
+	assert.Equal(t, email, claims.Email)
+}
+
+func TestVerifier_VerifyAccessToken_invalid(t *testing.T) {
+
+	// given
+	key := generateKey(t)
+	otherKey := generateKey(t)
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{
+			"token_use": TokenUseAccess,
+			"client_id": clientID,
+			"iss":       verifierIssuer,
+			"iat":       time.Now().Unix(),
+			"exp":       time.Now().Add(time.Hour).Unix(),
+		}
+	}
+	with := func(name string, value interface{}) map[string]interface{} {
+		claims := valid()
+		claims[name] = value
+		return claims
+	}
+
+	var testData = []struct {
+		token           string
+		expectedExpired bool
+	}{
+		{"not-a-token", false},
+		{signToken(t, otherKey, verifierKid, valid()), false},
+		{signToken(t, key, "unknown-key", valid()), false},
+		{signToken(t, key, verifierKid, with("iss", "https://example.com")), false},
+		{signToken(t, key, verifierKid, with("token_use", TokenUseID)), false},
+		{signToken(t, key, verifierKid, with("client_id", "other-client")), false},
+		{signToken(t, key, verifierKid, with("iat", time.Now().Add(time.Hour).Unix())), false},
+		{signToken(t, key, verifierKid, with("exp", time.Now().Add(-2*time.Minute).Unix())), true},
+	}
+
+	verifier := newTestVerifier(key)
+	for _, data := range testData {
+
+		// when
+		claims, err := verifier.VerifyAccessToken(data.token)
+
+		// then
+		assert.Error(t, err)
+		assert.True(t, toAwsgoError(t, err).InvalidToken())
+		assert.Equal(t, data.expectedExpired, toAwsgoError(t, err).TokenExpired())
+		assert.Nil(t, claims)
+	}
+}
+
+func TestVerifier_VerifyAccessToken_clockSkew(t *testing.T) {
+
+	// given
+	key := generateKey(t)
+	verifier := newTestVerifier(key, ClockSkew(5*time.Minute))
+	token := signToken(t, key, verifierKid, map[string]interface{}{
+		"token_use": TokenUseAccess,
+		"client_id": clientID,
+		"iss":       verifierIssuer,
+		"iat":       time.Now().Add(-time.Hour).Unix(),
+		"exp":       time.Now().Add(-2 * time.Minute).Unix(),
+	})
+
+	// when
+	_, err := verifier.VerifyAccessToken(token)
+
+	// then
+	assert.NoError(t, err)
+}
+
+func TestVerifier_keysCached(t *testing.T) {
+
+	// given
+	key := generateKey(t)
+	source := &staticJWKSSource{jwks: toJWKS(key)}
+	verifier := NewVerifier(verifierPoolID, clientID, VerifierJWKSSource(source))
+	token := signToken(t, key, verifierKid, map[string]interface{}{})
+
+	// when
+	_, _ = verifier.VerifyAccessToken(token)
+	_, _ = verifier.VerifyAccessToken(token)
+	_, _ = verifier.VerifyAccessToken(signToken(t, key, "unknown-key", map[string]interface{}{}))
+
+	// then
+	assert.Equal(t, 1, source.calls)
+}
+
+func TestVerifier_singleFetch(t *testing.T) {
+
+	// given
+	key := generateKey(t)
+	source := &blockingJWKSSource{jwks: toJWKS(key), release: make(chan struct{})}
+	verifier := NewVerifier(verifierPoolID, clientID, VerifierJWKSSource(source))
+	token := signToken(t, key, verifierKid, map[string]interface{}{})
+
+	// when
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = verifier.key(verifierKid)
+		}(i)
+	}
+	close(source.release)
+	wg.Wait()
+	_, _ = verifier.VerifyAccessToken(token)
+
+	// then
+	assert.Equal(t, int32(1), atomic.LoadInt32(&source.calls))
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+}
+
+func TestVerifier_fetchFailed(t *testing.T) {
+
+	// given
+	key := generateKey(t)
+	source := &blockingJWKSSource{err: errors.New("connection refused"), release: make(chan struct{})}
+	close(source.release)
+	now := time.Now()
+	verifier := NewVerifier(verifierPoolID, clientID, VerifierJWKSSource(source))
+	verifier.now = func() time.Time { return now }
+	token := signToken(t, key, verifierKid, map[string]interface{}{})
+
+	// when
+	_, err := verifier.VerifyAccessToken(token)
+	_, rateLimitedErr := verifier.VerifyAccessToken(token)
+	callsWithinMinute := atomic.LoadInt32(&source.calls)
+	now = now.Add(defaultJWKSRefreshRate)
+	_, _ = verifier.VerifyAccessToken(token)
+
+	// then
+	assert.True(t, toAwsgoError(t, err).JWKSFetchFailed())
+	assert.True(t, toAwsgoError(t, rateLimitedErr).JWKSFetchFailed())
+	assert.Equal(t, int32(1), callsWithinMinute)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&source.calls))
+}
+
+func newTestVerifier(key *rsa.PrivateKey, options ...func(*VerifierConfig)) *Verifier {
+	source := &staticJWKSSource{jwks: toJWKS(key)}
+	return NewVerifier(verifierPoolID, clientID, append(options, VerifierJWKSSource(source))...)
+}
+
+func generateKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func toJWKS(key *rsa.PrivateKey) *JWKS {
+	return &JWKS{Keys: []JWK{{
+		Kid: verifierKid,
+		Kty: "RSA",
+		Alg: "RS256",
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}}
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": kid})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
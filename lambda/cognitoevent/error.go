@@ -0,0 +1,26 @@
+package cognitoevent
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrNoHandler       = errors.New("no handler registered")
+	ErrInvalidResponse = errors.New("invalid response")
+)
+
+// TriggerError is returned to Cognito when the event could not be handled, which fails the user operation.
+type TriggerError struct {
+	TriggerSource string
+	Err           error
+}
+
+func (e *TriggerError) Error() string {
+	return fmt.Sprintf("%s trigger failed: %s", e.TriggerSource, e.Err)
+}
+
+func (e *TriggerError) Cause() error {
+	return e.Err
+}
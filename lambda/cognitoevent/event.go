@@ -0,0 +1,221 @@
+package cognitoevent
+
+import (
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/pkg/errors"
+)
+
+// Trigger types, the part of triggerSource before underscore e.g. PreSignUp for PreSignUp_SignUp.
+const (
+	PreSignUp                   = "PreSignUp"
+	PreTokenGeneration          = "TokenGeneration"
+	CustomMessage               = "CustomMessage"
+	DefineAuthChallenge         = "DefineAuthChallenge"
+	CreateAuthChallenge         = "CreateAuthChallenge"
+	VerifyAuthChallengeResponse = "VerifyAuthChallengeResponse"
+	UserMigration               = "UserMigration"
+)
+
+const (
+	UserStatusConfirmed     = "CONFIRMED"
+	UserStatusResetRequired = "RESET_REQUIRED"
+
+	MessageActionSuppress = "SUPPRESS"
+	MessageActionResend   = "RESEND"
+
+	customMessageAdminCreateUser = "CustomMessage_AdminCreateUser"
+)
+
+type CallerContext struct {
+	AWSSDKVersion string `json:"awsSdkVersion"`
+	ClientID      string `json:"clientId"`
+}
+
+// Header holds fields common to all trigger events.
+type Header struct {
+	Version       string        `json:"version"`
+	TriggerSource string        `json:"triggerSource"`
+	Region        string        `json:"region"`
+	UserPoolID    string        `json:"userPoolId"`
+	UserName      string        `json:"userName"`
+	CallerContext CallerContext `json:"callerContext"`
+}
+
+type CustomMessageEvent struct {
+	Header
+	Request struct {
+		UserAttributes    map[string]string `json:"userAttributes"`
+		CodeParameter     string            `json:"codeParameter"`
+		UsernameParameter string            `json:"usernameParameter"`
+		ClientMetadata    map[string]string `json:"clientMetadata"`
+	} `json:"request"`
+	Response struct {
+		SMSMessage   string `json:"smsMessage,omitempty"`
+		EmailMessage string `json:"emailMessage,omitempty"`
+		EmailSubject string `json:"emailSubject,omitempty"`
+	} `json:"response"`
+}
+
+type ChallengeResult struct {
+	ChallengeName     string `json:"challengeName"`
+	ChallengeResult   bool   `json:"challengeResult"`
+	ChallengeMetadata string `json:"challengeMetadata,omitempty"`
+}
+
+type DefineAuthChallengeEvent struct {
+	Header
+	Request struct {
+		UserAttributes map[string]string  `json:"userAttributes"`
+		Session        []*ChallengeResult `json:"session"`
+		UserNotFound   bool               `json:"userNotFound"`
+		ClientMetadata map[string]string  `json:"clientMetadata"`
+	} `json:"request"`
+	Response struct {
+		ChallengeName      string `json:"challengeName,omitempty"`
+		IssueTokens        bool   `json:"issueTokens"`
+		FailAuthentication bool   `json:"failAuthentication"`
+	} `json:"response"`
+}
+
+type CreateAuthChallengeEvent struct {
+	Header
+	Request struct {
+		UserAttributes map[string]string  `json:"userAttributes"`
+		ChallengeName  string             `json:"challengeName"`
+		Session        []*ChallengeResult `json:"session"`
+		UserNotFound   bool               `json:"userNotFound"`
+		ClientMetadata map[string]string  `json:"clientMetadata"`
+	} `json:"request"`
+	Response struct {
+		PublicChallengeParameters  map[string]string `json:"publicChallengeParameters"`
+		PrivateChallengeParameters map[string]string `json:"privateChallengeParameters"`
+		ChallengeMetadata          string            `json:"challengeMetadata,omitempty"`
+	} `json:"response"`
+}
+
+type VerifyAuthChallengeEvent struct {
+	Header
+	Request struct {
+		UserAttributes             map[string]string `json:"userAttributes"`
+		PrivateChallengeParameters map[string]string `json:"privateChallengeParameters"`
+		ChallengeAnswer            string            `json:"challengeAnswer"`
+		UserNotFound               bool              `json:"userNotFound"`
+		ClientMetadata             map[string]string `json:"clientMetadata"`
+	} `json:"request"`
+	Response struct {
+		AnswerCorrect bool `json:"answerCorrect"`
+	} `json:"response"`
+}
+
+type UserMigrationEvent struct {
+	Header
+	Request struct {
+		Password       string            `json:"password"`
+		ValidationData map[string]string `json:"validationData"`
+		ClientMetadata map[string]string `json:"clientMetadata"`
+	} `json:"request"`
+	Response struct {
+		UserAttributes         map[string]string `json:"userAttributes"`
+		FinalUserStatus        string            `json:"finalUserStatus,omitempty"`
+		MessageAction          string            `json:"messageAction,omitempty"`
+		DesiredDeliveryMediums []string          `json:"desiredDeliveryMediums,omitempty"`
+		ForceAliasCreation     bool              `json:"forceAliasCreation"`
+	} `json:"response"`
+}
+
+// TriggerType returns the part of triggerSource before underscore.
+func (h *Header) TriggerType() string {
+	return strings.SplitN(h.TriggerSource, "_", 2)[0]
+}
+
+// preSignUpTrigger validates events.CognitoEventUserPoolsPreSignup, aws-lambda-go v1.10.0 does not decode
+// clientMetadata of the request.
+type preSignUpTrigger struct {
+	*events.CognitoEventUserPoolsPreSignup
+}
+
+// preTokenGenerationTrigger validates events.CognitoEventUserPoolsPreTokenGen, aws-lambda-go v1.10.0 does not decode
+// clientMetadata of the request.
+type preTokenGenerationTrigger struct {
+	*events.CognitoEventUserPoolsPreTokenGen
+}
+
+// validate checks that auto verified attributes are present, Cognito fails the sign up otherwise.
+func (t preSignUpTrigger) validate() error {
+	if t.Response.AutoVerifyEmail && t.Request.UserAttributes["email"] == "" {
+		return invalidResponse("autoVerifyEmail requires email attribute")
+	}
+	if t.Response.AutoVerifyPhone && t.Request.UserAttributes["phone_number"] == "" {
+		return invalidResponse("autoVerifyPhone requires phone_number attribute")
+	}
+	return nil
+}
+
+func (t preTokenGenerationTrigger) validate() error {
+	details := t.Response.ClaimsOverrideDetails
+	for _, claim := range details.ClaimsToSuppress {
+		if _, ok := details.ClaimsToAddOrOverride[claim]; ok {
+			return invalidResponse("claim " + claim + " is both overridden and suppressed")
+		}
+	}
+	return nil
+}
+
+// validate drops custom messages missing the placeholders Cognito replaces, so the default message is sent instead
+// of failing the user operation.
+func (e *CustomMessageEvent) validate() error {
+	for _, message := range []string{e.Response.SMSMessage, e.Response.EmailMessage} {
+		if message == "" {
+			continue
+		}
+		missingUsername := e.TriggerSource == customMessageAdminCreateUser &&
+			!strings.Contains(message, e.Request.UsernameParameter)
+		if !strings.Contains(message, e.Request.CodeParameter) || missingUsername {
+			e.Response.SMSMessage, e.Response.EmailMessage, e.Response.EmailSubject = "", "", ""
+			return nil
+		}
+	}
+	return nil
+}
+
+func (e *DefineAuthChallengeEvent) validate() error {
+	switch r := e.Response; {
+	case r.IssueTokens && r.FailAuthentication:
+		return invalidResponse("issueTokens and failAuthentication are exclusive")
+	case !r.IssueTokens && !r.FailAuthentication && r.ChallengeName == "":
+		return invalidResponse("challengeName is required unless authentication ends")
+	}
+	return nil
+}
+
+func (e *CreateAuthChallengeEvent) validate() error {
+	return nil
+}
+
+func (e *VerifyAuthChallengeEvent) validate() error {
+	return nil
+}
+
+func (e *UserMigrationEvent) validate() error {
+	r := e.Response
+	switch r.FinalUserStatus {
+	case "", UserStatusConfirmed, UserStatusResetRequired:
+	default:
+		return invalidResponse("unsupported finalUserStatus " + r.FinalUserStatus)
+	}
+	switch r.MessageAction {
+	case "", MessageActionSuppress, MessageActionResend:
+	default:
+		return invalidResponse("unsupported messageAction " + r.MessageAction)
+	}
+	if len(r.UserAttributes) == 0 {
+		return invalidResponse("userAttributes are required")
+	}
+	return nil
+}
+
+func invalidResponse(reason string) error {
+	return errors.Wrap(ErrInvalidResponse, reason)
+}
@@ -0,0 +1,196 @@
+package cognitoevent
+
+import (
+	"encoding/json"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Handlers fill in the response of the event, returning an error fails the user operation in Cognito.
+type (
+	PreSignUpHandler interface {
+		HandlePreSignUp(*events.CognitoEventUserPoolsPreSignup) error
+	}
+	PreTokenGenerationHandler interface {
+		HandlePreTokenGeneration(*events.CognitoEventUserPoolsPreTokenGen) error
+	}
+	CustomMessageHandler interface {
+		HandleCustomMessage(*CustomMessageEvent) error
+	}
+	DefineAuthChallengeHandler interface {
+		HandleDefineAuthChallenge(*DefineAuthChallengeEvent) error
+	}
+	CreateAuthChallengeHandler interface {
+		HandleCreateAuthChallenge(*CreateAuthChallengeEvent) error
+	}
+	VerifyAuthChallengeHandler interface {
+		HandleVerifyAuthChallenge(*VerifyAuthChallengeEvent) error
+	}
+	UserMigrationHandler interface {
+		HandleUserMigration(*UserMigrationEvent) error
+	}
+)
+
+type (
+	PreSignUpHandlerFunc           func(*events.CognitoEventUserPoolsPreSignup) error
+	PreTokenGenerationHandlerFunc  func(*events.CognitoEventUserPoolsPreTokenGen) error
+	CustomMessageHandlerFunc       func(*CustomMessageEvent) error
+	DefineAuthChallengeHandlerFunc func(*DefineAuthChallengeEvent) error
+	CreateAuthChallengeHandlerFunc func(*CreateAuthChallengeEvent) error
+	VerifyAuthChallengeHandlerFunc func(*VerifyAuthChallengeEvent) error
+	UserMigrationHandlerFunc       func(*UserMigrationEvent) error
+)
+
+func (f PreSignUpHandlerFunc) HandlePreSignUp(e *events.CognitoEventUserPoolsPreSignup) error {
+	return f(e)
+}
+
+func (f PreTokenGenerationHandlerFunc) HandlePreTokenGeneration(e *events.CognitoEventUserPoolsPreTokenGen) error {
+	return f(e)
+}
+
+func (f CustomMessageHandlerFunc) HandleCustomMessage(e *CustomMessageEvent) error {
+	return f(e)
+}
+
+func (f DefineAuthChallengeHandlerFunc) HandleDefineAuthChallenge(e *DefineAuthChallengeEvent) error {
+	return f(e)
+}
+
+func (f CreateAuthChallengeHandlerFunc) HandleCreateAuthChallenge(e *CreateAuthChallengeEvent) error {
+	return f(e)
+}
+
+func (f VerifyAuthChallengeHandlerFunc) HandleVerifyAuthChallenge(e *VerifyAuthChallengeEvent) error {
+	return f(e)
+}
+
+func (f UserMigrationHandlerFunc) HandleUserMigration(e *UserMigrationEvent) error {
+	return f(e)
+}
+
+type trigger interface {
+	validate() error
+}
+
+type Dispatcher struct {
+	handlers map[string]func(json.RawMessage) (trigger, error)
+}
+
+type LambdaHandler func(json.RawMessage) (json.RawMessage, error)
+
+// WrapHandler returns lambda handler which decodes the event by its triggerSource, passes it to the registered handler
+// and returns the event with validated response back to Cognito. A single lambda may serve many triggers.
+func WrapHandler(options ...func(*Dispatcher)) LambdaHandler {
+	d := &Dispatcher{
+		handlers: make(map[string]func(json.RawMessage) (trigger, error)),
+	}
+	for _, opt := range options {
+		opt(d)
+	}
+
+	return d.dispatch
+}
+
+func OnPreSignUp(handler PreSignUpHandler) func(*Dispatcher) {
+	return on(PreSignUp, func(data json.RawMessage) (trigger, error) {
+		event := &events.CognitoEventUserPoolsPreSignup{}
+		if err := json.Unmarshal(data, event); err != nil {
+			return nil, err
+		}
+		return preSignUpTrigger{event}, handler.HandlePreSignUp(event)
+	})
+}
+
+func OnPreTokenGeneration(handler PreTokenGenerationHandler) func(*Dispatcher) {
+	return on(PreTokenGeneration, func(data json.RawMessage) (trigger, error) {
+		event := &events.CognitoEventUserPoolsPreTokenGen{}
+		if err := json.Unmarshal(data, event); err != nil {
+			return nil, err
+		}
+		return preTokenGenerationTrigger{event}, handler.HandlePreTokenGeneration(event)
+	})
+}
+
+func OnCustomMessage(handler CustomMessageHandler) func(*Dispatcher) {
+	return on(CustomMessage, func(data json.RawMessage) (trigger, error) {
+		event := &CustomMessageEvent{}
+		if err := json.Unmarshal(data, event); err != nil {
+			return nil, err
+		}
+		return event, handler.HandleCustomMessage(event)
+	})
+}
+
+func OnDefineAuthChallenge(handler DefineAuthChallengeHandler) func(*Dispatcher) {
+	return on(DefineAuthChallenge, func(data json.RawMessage) (trigger, error) {
+		event := &DefineAuthChallengeEvent{}
+		if err := json.Unmarshal(data, event); err != nil {
+			return nil, err
+		}
+		return event, handler.HandleDefineAuthChallenge(event)
+	})
+}
+
+func OnCreateAuthChallenge(handler CreateAuthChallengeHandler) func(*Dispatcher) {
+	return on(CreateAuthChallenge, func(data json.RawMessage) (trigger, error) {
+		event := &CreateAuthChallengeEvent{}
+		if err := json.Unmarshal(data, event); err != nil {
+			return nil, err
+		}
+		return event, handler.HandleCreateAuthChallenge(event)
+	})
+}
+
+func OnVerifyAuthChallenge(handler VerifyAuthChallengeHandler) func(*Dispatcher) {
+	return on(VerifyAuthChallengeResponse, func(data json.RawMessage) (trigger, error) {
+		event := &VerifyAuthChallengeEvent{}
+		if err := json.Unmarshal(data, event); err != nil {
+			return nil, err
+		}
+		return event, handler.HandleVerifyAuthChallenge(event)
+	})
+}
+
+// OnUserMigration registers handler for user migration, returning an error tells Cognito that the user does not exist.
+func OnUserMigration(handler UserMigrationHandler) func(*Dispatcher) {
+	return on(UserMigration, func(data json.RawMessage) (trigger, error) {
+		event := &UserMigrationEvent{}
+		if err := json.Unmarshal(data, event); err != nil {
+			return nil, err
+		}
+		return event, handler.HandleUserMigration(event)
+	})
+}
+
+func on(triggerType string, handle func(json.RawMessage) (trigger, error)) func(*Dispatcher) {
+	return func(d *Dispatcher) {
+		d.handlers[triggerType] = handle
+	}
+}
+
+func (d *Dispatcher) dispatch(data json.RawMessage) (json.RawMessage, error) {
+	header := &Header{}
+	if err := json.Unmarshal(data, header); err != nil {
+		return nil, &TriggerError{Err: err}
+	}
+
+	handle, ok := d.handlers[header.TriggerType()]
+	if !ok {
+		return nil, &TriggerError{TriggerSource: header.TriggerSource, Err: ErrNoHandler}
+	}
+
+	event, err := handle(data)
+	if err == nil {
+		err = event.validate()
+	}
+	if err != nil {
+		return nil, &TriggerError{TriggerSource: header.TriggerSource, Err: err}
+	}
+
+	response, err := json.Marshal(event)
+	if err != nil {
+		return nil, &TriggerError{TriggerSource: header.TriggerSource, Err: err}
+	}
+	return response, nil
+}
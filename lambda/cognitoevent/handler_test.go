@@ -0,0 +1,236 @@
+package cognitoevent_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Ryanair/goaws/lambda/cognitoevent"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const preSignUpEvent = `{
+	"version": "1",
+	"triggerSource": "PreSignUp_SignUp",
+	"region": "eu-west-1",
+	"userPoolId": "eu-west-1_abcdef",
+	"userName": "john",
+	"callerContext": {"awsSdkVersion": "aws-sdk-js-2.6.4", "clientId": "client-id"},
+	"request": {"userAttributes": {"email": "john@example.com"}, "validationData": {"source": "web"}},
+	"response": {}
+}`
+
+func TestWrapHandler_preSignUp(t *testing.T) {
+	// given
+	var received *events.CognitoEventUserPoolsPreSignup
+	wrappedHandler := cognitoevent.WrapHandler(cognitoevent.OnPreSignUp(cognitoevent.PreSignUpHandlerFunc(
+		func(event *events.CognitoEventUserPoolsPreSignup) error {
+			received = event
+			event.Response.AutoConfirmUser = true
+			event.Response.AutoVerifyEmail = true
+			return nil
+		})))
+
+	// when
+	resp, err := wrappedHandler(json.RawMessage(preSignUpEvent))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "john", received.UserName)
+	assert.Equal(t, "client-id", received.CallerContext.ClientID)
+	assert.Equal(t, "web", received.Request.ValidationData["source"])
+
+	var decoded map[string]interface{}
+	assert.NoError(t, json.Unmarshal(resp, &decoded))
+	assert.Equal(t, "PreSignUp_SignUp", decoded["triggerSource"])
+	assert.Equal(t, map[string]interface{}{
+		"autoConfirmUser": true,
+		"autoVerifyEmail": true,
+		"autoVerifyPhone": false,
+	}, decoded["response"])
+}
+
+func TestWrapHandler_dispatchesByTriggerSource(t *testing.T) {
+	// given
+	var preSignUpCalls, migrationCalls int
+	wrappedHandler := cognitoevent.WrapHandler(
+		cognitoevent.OnPreSignUp(cognitoevent.PreSignUpHandlerFunc(func(*events.CognitoEventUserPoolsPreSignup) error {
+			preSignUpCalls++
+			return nil
+		})),
+		cognitoevent.OnUserMigration(cognitoevent.UserMigrationHandlerFunc(func(event *cognitoevent.UserMigrationEvent) error {
+			migrationCalls++
+			event.Response.UserAttributes = map[string]string{"email": "john@example.com"}
+			event.Response.FinalUserStatus = cognitoevent.UserStatusConfirmed
+			return nil
+		})),
+	)
+
+	// when
+	_, migrationErr := wrappedHandler(json.RawMessage(`{"triggerSource": "UserMigration_Authentication", "request": {"password": "secret"}}`))
+	_, noHandlerErr := wrappedHandler(json.RawMessage(`{"triggerSource": "CustomMessage_SignUp"}`))
+
+	// then
+	assert.NoError(t, migrationErr)
+	assert.Equal(t, 0, preSignUpCalls)
+	assert.Equal(t, 1, migrationCalls)
+	assert.Equal(t, cognitoevent.ErrNoHandler, errors.Cause(noHandlerErr))
+}
+
+func TestWrapHandler_handlerError(t *testing.T) {
+	// given
+	handlerErr := errors.New("user not found")
+	wrappedHandler := cognitoevent.WrapHandler(cognitoevent.OnUserMigration(cognitoevent.UserMigrationHandlerFunc(
+		func(*cognitoevent.UserMigrationEvent) error {
+			return handlerErr
+		})))
+
+	// when
+	resp, err := wrappedHandler(json.RawMessage(`{"triggerSource": "UserMigration_ForgotPassword"}`))
+
+	// then
+	assert.Nil(t, resp)
+	assert.Equal(t, handlerErr, errors.Cause(err))
+	assert.EqualError(t, err, "UserMigration_ForgotPassword trigger failed: user not found")
+}
+
+func TestWrapHandler_invalidResponses(t *testing.T) {
+	var testData = []struct {
+		event   string
+		handler func(*cognitoevent.Dispatcher)
+	}{
+		{`{"triggerSource": "PreSignUp_SignUp", "request": {"userAttributes": {}}}`,
+			cognitoevent.OnPreSignUp(cognitoevent.PreSignUpHandlerFunc(func(event *events.CognitoEventUserPoolsPreSignup) error {
+				event.Response.AutoVerifyPhone = true
+				return nil
+			}))},
+		{`{"triggerSource": "TokenGeneration_Authentication"}`,
+			cognitoevent.OnPreTokenGeneration(cognitoevent.PreTokenGenerationHandlerFunc(func(event *events.CognitoEventUserPoolsPreTokenGen) error {
+				event.Response.ClaimsOverrideDetails = events.ClaimsOverrideDetails{
+					ClaimsToAddOrOverride: map[string]string{"email": "other@example.com"},
+					ClaimsToSuppress:      []string{"email"},
+				}
+				return nil
+			}))},
+		{`{"triggerSource": "DefineAuthChallenge_Authentication"}`,
+			cognitoevent.OnDefineAuthChallenge(cognitoevent.DefineAuthChallengeHandlerFunc(func(event *cognitoevent.DefineAuthChallengeEvent) error {
+				return nil
+			}))},
+		{`{"triggerSource": "DefineAuthChallenge_Authentication"}`,
+			cognitoevent.OnDefineAuthChallenge(cognitoevent.DefineAuthChallengeHandlerFunc(func(event *cognitoevent.DefineAuthChallengeEvent) error {
+				event.Response.IssueTokens = true
+				event.Response.FailAuthentication = true
+				return nil
+			}))},
+		{`{"triggerSource": "UserMigration_Authentication"}`,
+			cognitoevent.OnUserMigration(cognitoevent.UserMigrationHandlerFunc(func(event *cognitoevent.UserMigrationEvent) error {
+				event.Response.UserAttributes = map[string]string{"email": "john@example.com"}
+				event.Response.FinalUserStatus = "UNCONFIRMED"
+				return nil
+			}))},
+	}
+
+	for _, data := range testData {
+		// given
+		wrappedHandler := cognitoevent.WrapHandler(data.handler)
+
+		// when
+		resp, err := wrappedHandler(json.RawMessage(data.event))
+
+		// then
+		assert.Nil(t, resp)
+		assert.Equal(t, cognitoevent.ErrInvalidResponse, errors.Cause(err), data.event)
+	}
+}
+
+func TestWrapHandler_customMessageDropped(t *testing.T) {
+	var testData = []struct {
+		event   string
+		message string
+	}{
+		{`{"triggerSource": "CustomMessage_SignUp", "request": {"codeParameter": "{####}"}}`, "Welcome!"},
+		{`{"triggerSource": "CustomMessage_AdminCreateUser", "request": {"codeParameter": "{####}", "usernameParameter": "{username}"}}`,
+			"Your code is {####}"},
+	}
+
+	for _, data := range testData {
+		// given
+		message := data.message
+		wrappedHandler := cognitoevent.WrapHandler(cognitoevent.OnCustomMessage(cognitoevent.CustomMessageHandlerFunc(
+			func(event *cognitoevent.CustomMessageEvent) error {
+				event.Response.EmailSubject = "Hello"
+				event.Response.EmailMessage = message
+				return nil
+			})))
+
+		// when
+		resp, err := wrappedHandler(json.RawMessage(data.event))
+
+		// then
+		var decoded map[string]interface{}
+		assert.NoError(t, err, data.event)
+		assert.NoError(t, json.Unmarshal(resp, &decoded))
+		assert.Equal(t, map[string]interface{}{}, decoded["response"], data.event)
+	}
+}
+
+func TestWrapHandler_customMessageKept(t *testing.T) {
+	// given
+	wrappedHandler := cognitoevent.WrapHandler(cognitoevent.OnCustomMessage(cognitoevent.CustomMessageHandlerFunc(
+		func(event *cognitoevent.CustomMessageEvent) error {
+			event.Response.SMSMessage = "Hi {username}, your code is {####}"
+			return nil
+		})))
+
+	// when
+	resp, err := wrappedHandler(json.RawMessage(`{"triggerSource": "CustomMessage_AdminCreateUser",
+		"request": {"codeParameter": "{####}", "usernameParameter": "{username}"}}`))
+
+	// then
+	assert.NoError(t, err)
+	assert.Contains(t, string(resp), `"smsMessage":"Hi {username}, your code is {####}"`)
+}
+
+func TestWrapHandler_authChallenge(t *testing.T) {
+	// given
+	wrappedHandler := cognitoevent.WrapHandler(
+		cognitoevent.OnDefineAuthChallenge(cognitoevent.DefineAuthChallengeHandlerFunc(func(event *cognitoevent.DefineAuthChallengeEvent) error {
+			session := event.Request.Session
+			if len(session) > 0 && session[len(session)-1].ChallengeResult {
+				event.Response.IssueTokens = true
+				return nil
+			}
+			event.Response.ChallengeName = "CUSTOM_CHALLENGE"
+			return nil
+		})),
+		cognitoevent.OnCreateAuthChallenge(cognitoevent.CreateAuthChallengeHandlerFunc(func(event *cognitoevent.CreateAuthChallengeEvent) error {
+			event.Response.PublicChallengeParameters = map[string]string{"question": "6 * 7"}
+			event.Response.PrivateChallengeParameters = map[string]string{"answer": "42"}
+			return nil
+		})),
+		cognitoevent.OnVerifyAuthChallenge(cognitoevent.VerifyAuthChallengeHandlerFunc(func(event *cognitoevent.VerifyAuthChallengeEvent) error {
+			event.Response.AnswerCorrect = event.Request.ChallengeAnswer == event.Request.PrivateChallengeParameters["answer"]
+			return nil
+		})),
+	)
+
+	// when
+	defineResp, defineErr := wrappedHandler(json.RawMessage(`{"triggerSource": "DefineAuthChallenge_Authentication", "request": {"session": []}}`))
+	createResp, createErr := wrappedHandler(json.RawMessage(`{"triggerSource": "CreateAuthChallenge_Authentication", "request": {"challengeName": "CUSTOM_CHALLENGE"}}`))
+	verifyResp, verifyErr := wrappedHandler(json.RawMessage(`{"triggerSource": "VerifyAuthChallengeResponse_Authentication",
+		"request": {"challengeAnswer": "42", "privateChallengeParameters": {"answer": "42"}}}`))
+	issueResp, issueErr := wrappedHandler(json.RawMessage(`{"triggerSource": "DefineAuthChallenge_Authentication",
+		"request": {"session": [{"challengeName": "CUSTOM_CHALLENGE", "challengeResult": true}]}}`))
+
+	// then
+	assert.NoError(t, defineErr)
+	assert.NoError(t, createErr)
+	assert.NoError(t, verifyErr)
+	assert.NoError(t, issueErr)
+	assert.Contains(t, string(defineResp), `"challengeName":"CUSTOM_CHALLENGE"`)
+	assert.Contains(t, string(createResp), `"publicChallengeParameters":{"question":"6 * 7"}`)
+	assert.Contains(t, string(verifyResp), `"answerCorrect":true`)
+	assert.Contains(t, string(issueResp), `"issueTokens":true`)
+}
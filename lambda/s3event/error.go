@@ -0,0 +1,35 @@
+package s3event
+
+import (
+	"fmt"
+	"strings"
+)
+
+type RecordError struct {
+	EventName string
+	Bucket    string
+	Key       string
+	Err       error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("%s event for %s/%s failed: %s", e.EventName, e.Bucket, e.Key, e.Err)
+}
+
+func (e *RecordError) Cause() error {
+	return e.Err
+}
+
+// HandlerError aggregates failures of all records processed within single S3 event.
+type HandlerError struct {
+	Records []*RecordError
+	Total   int
+}
+
+func (e *HandlerError) Error() string {
+	msgs := make([]string, 0, len(e.Records))
+	for _, r := range e.Records {
+		msgs = append(msgs, r.Error())
+	}
+	return fmt.Sprintf("%d of %d s3 records failed: %s", len(e.Records), e.Total, strings.Join(msgs, "; "))
+}
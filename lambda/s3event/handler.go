@@ -0,0 +1,183 @@
+package s3event
+
+import (
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/Ryanair/goaws/s3"
+
+	"github.com/aws/aws-lambda-go/events"
+	awss3 "github.com/aws/aws-sdk-go/service/s3"
+)
+
+const defaultConcurrency = 10
+
+type handler interface {
+	Handle(*Record) error
+}
+
+type HandlerFunc func(*Record) error
+
+func (f HandlerFunc) Handle(record *Record) error {
+	return f(record)
+}
+
+type objectFetcher interface {
+	GetObject(bucket, key string, options ...func(*awss3.GetObjectInput)) (io.ReadCloser, error)
+	HeadObject(bucket, key string, options ...func(*awss3.HeadObjectInput)) (*s3.HeadObjectResult, error)
+}
+
+type Dispatcher struct {
+	handlers    map[string]handler
+	fallback    handler
+	bodyFetcher objectFetcher
+	metaFetcher objectFetcher
+	concurrency int
+}
+
+type LambdaHandler func(*events.S3Event) error
+
+// WrapHandler returns lambda handler which dispatches every record of the event to the handler registered for its
+// event type, records without a dedicated handler go to the given one, which may be nil to skip them.
+func WrapHandler(fallback handler, options ...func(*Dispatcher)) LambdaHandler {
+	d := &Dispatcher{
+		handlers:    make(map[string]handler),
+		fallback:    fallback,
+		concurrency: defaultConcurrency,
+	}
+	for _, opt := range options {
+		opt(d)
+	}
+
+	return d.dispatch
+}
+
+func OnObjectCreated(handler handler) func(*Dispatcher) {
+	return OnEvent(ObjectCreated, handler)
+}
+
+func OnObjectRemoved(handler handler) func(*Dispatcher) {
+	return OnEvent(ObjectRemoved, handler)
+}
+
+// OnEvent registers handler for event type, which is the part of event name before colon e.g. ObjectCreated.
+func OnEvent(eventType string, handler handler) func(*Dispatcher) {
+	return func(d *Dispatcher) {
+		d.handlers[eventType] = handler
+	}
+}
+
+// FetchBody makes the object body available in Record.Body, it is not fetched for ObjectRemoved events.
+func FetchBody(fetcher objectFetcher) func(*Dispatcher) {
+	return func(d *Dispatcher) {
+		d.bodyFetcher = fetcher
+	}
+}
+
+// FetchMetadata makes the object metadata available in Record.Metadata, it is not fetched for ObjectRemoved events.
+func FetchMetadata(fetcher objectFetcher) func(*Dispatcher) {
+	return func(d *Dispatcher) {
+		d.metaFetcher = fetcher
+	}
+}
+
+func Concurrency(n int) func(*Dispatcher) {
+	return func(d *Dispatcher) {
+		if n > 0 {
+			d.concurrency = n
+		}
+	}
+}
+
+func (d *Dispatcher) dispatch(event *events.S3Event) error {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed []*RecordError
+		sem    = make(chan struct{}, d.concurrency)
+	)
+	for i := range event.Records {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(eventRecord *events.S3EventRecord) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := d.handle(eventRecord); err != nil {
+				mu.Lock()
+				failed = append(failed, err)
+				mu.Unlock()
+			}
+		}(&event.Records[i])
+	}
+	wg.Wait()
+
+	if len(failed) > 0 {
+		return &HandlerError{Records: failed, Total: len(event.Records)}
+	}
+	return nil
+}
+
+func (d *Dispatcher) handle(eventRecord *events.S3EventRecord) *RecordError {
+	recordErr := func(err error) *RecordError {
+		return &RecordError{
+			EventName: eventRecord.EventName,
+			Bucket:    eventRecord.S3.Bucket.Name,
+			Key:       eventRecord.S3.Object.Key,
+			Err:       err,
+		}
+	}
+
+	h := d.handlerFor(eventRecord.EventName)
+	if h == nil {
+		return nil
+	}
+
+	record, err := NewRecord(eventRecord)
+	if err != nil {
+		return recordErr(err)
+	}
+
+	if eventType(record.EventName) != ObjectRemoved {
+		// versioned buckets report the version which triggered the event, which may no longer be the current one
+		var (
+			headOptions []func(*awss3.HeadObjectInput)
+			getOptions  []func(*awss3.GetObjectInput)
+		)
+		if record.VersionID != "" {
+			headOptions = append(headOptions, s3.HeadVersionID(record.VersionID))
+			getOptions = append(getOptions, s3.GetVersionID(record.VersionID))
+		}
+
+		if d.metaFetcher != nil {
+			if record.Metadata, err = d.metaFetcher.HeadObject(record.Bucket, record.Key, headOptions...); err != nil {
+				return recordErr(err)
+			}
+		}
+		if d.bodyFetcher != nil {
+			if record.Body, err = d.bodyFetcher.GetObject(record.Bucket, record.Key, getOptions...); err != nil {
+				return recordErr(err)
+			}
+			defer record.Body.Close()
+		}
+	}
+
+	if err := h.Handle(record); err != nil {
+		return recordErr(err)
+	}
+	return nil
+}
+
+func (d *Dispatcher) handlerFor(eventName string) handler {
+	if h, ok := d.handlers[eventType(eventName)]; ok {
+		return h
+	}
+	return d.fallback
+}
+
+func eventType(eventName string) string {
+	return strings.SplitN(eventName, ":", 2)[0]
+}
@@ -0,0 +1,205 @@
+package s3event_test
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Ryanair/goaws/lambda/s3event"
+	"github.com/Ryanair/goaws/s3"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	awss3 "github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockHandler struct {
+	mu     sync.Mutex
+	calls  []*s3event.Record
+	bodies []string
+	error
+}
+
+func (h *mockHandler) Handle(record *s3event.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.calls = append(h.calls, record)
+	if record.Body != nil {
+		body, _ := ioutil.ReadAll(record.Body)
+		h.bodies = append(h.bodies, string(body))
+	}
+	return h.error
+}
+
+type mockFetcher struct {
+	objects map[string]string
+}
+
+// objects are keyed by bucket/key, or bucket/key@version when the version is requested
+func (f *mockFetcher) GetObject(bucket, key string, options ...func(*awss3.GetObjectInput)) (io.ReadCloser, error) {
+	input := &awss3.GetObjectInput{}
+	for _, opt := range options {
+		opt(input)
+	}
+	body, ok := f.objects[objectID(bucket, key, input.VersionId)]
+	if !ok {
+		return nil, errors.New("no such key")
+	}
+	return ioutil.NopCloser(strings.NewReader(body)), nil
+}
+
+func (f *mockFetcher) HeadObject(bucket, key string, options ...func(*awss3.HeadObjectInput)) (*s3.HeadObjectResult, error) {
+	input := &awss3.HeadObjectInput{}
+	for _, opt := range options {
+		opt(input)
+	}
+	body, ok := f.objects[objectID(bucket, key, input.VersionId)]
+	if !ok {
+		return nil, errors.New("no such key")
+	}
+	return &s3.HeadObjectResult{ContentLength: aws.Int64(int64(len(body)))}, nil
+}
+
+func objectID(bucket, key string, versionID *string) string {
+	if versionID != nil {
+		return bucket + "/" + key + "@" + *versionID
+	}
+	return bucket + "/" + key
+}
+
+func record(eventName, bucket, key string) events.S3EventRecord {
+	return events.S3EventRecord{
+		EventName: eventName,
+		S3: events.S3Entity{
+			Bucket: events.S3Bucket{Name: bucket},
+			Object: events.S3Object{Key: key},
+		},
+	}
+}
+
+func TestWrapHandler_decodesKeys(t *testing.T) {
+	// given
+	event := &events.S3Event{Records: []events.S3EventRecord{
+		record("ObjectCreated:Put", "invoices", "2019/Invoice+%231.pdf"),
+	}}
+	mockHandler := &mockHandler{}
+	wrappedHandler := s3event.WrapHandler(mockHandler)
+
+	// when
+	err := wrappedHandler(event)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, &s3event.Record{
+		EventName: "ObjectCreated:Put",
+		Bucket:    "invoices",
+		Key:       "2019/Invoice #1.pdf",
+	}, mockHandler.calls[0])
+}
+
+func TestWrapHandler_dispatchesByEventType(t *testing.T) {
+	// given
+	event := &events.S3Event{Records: []events.S3EventRecord{
+		record("ObjectCreated:Put", "bucket", "created"),
+		record("ObjectRemoved:Delete", "bucket", "removed"),
+		record("ObjectRestore:Completed", "bucket", "restored"),
+	}}
+	created, removed := &mockHandler{}, &mockHandler{}
+	wrappedHandler := s3event.WrapHandler(nil, s3event.OnObjectCreated(created), s3event.OnObjectRemoved(removed))
+
+	// when
+	err := wrappedHandler(event)
+
+	// then
+	assert.NoError(t, err)
+	assert.Len(t, created.calls, 1)
+	assert.Len(t, removed.calls, 1)
+	assert.Equal(t, "created", created.calls[0].Key)
+	assert.Equal(t, "removed", removed.calls[0].Key)
+}
+
+func TestWrapHandler_fetchesObjects(t *testing.T) {
+	// given
+	event := &events.S3Event{Records: []events.S3EventRecord{
+		record("ObjectCreated:Put", "bucket", "a"),
+		record("ObjectCreated:Put", "bucket", "b"),
+		record("ObjectRemoved:Delete", "bucket", "c"),
+	}}
+	fetcher := &mockFetcher{objects: map[string]string{"bucket/a": "abc", "bucket/b": "abc"}}
+	mockHandler := &mockHandler{}
+	wrappedHandler := s3event.WrapHandler(mockHandler, s3event.FetchBody(fetcher), s3event.FetchMetadata(fetcher))
+
+	// when
+	err := wrappedHandler(event)
+
+	// then
+	assert.NoError(t, err)
+	assert.Len(t, mockHandler.calls, 3)
+	assert.Equal(t, []string{"abc", "abc"}, mockHandler.bodies)
+	for _, call := range mockHandler.calls {
+		if call.Key == "c" {
+			assert.Nil(t, call.Metadata)
+			continue
+		}
+		assert.Equal(t, int64(3), *call.Metadata.ContentLength)
+	}
+}
+
+func TestWrapHandler_fetchesEventVersion(t *testing.T) {
+	// given
+	versioned := record("ObjectCreated:Put", "bucket", "a")
+	versioned.S3.Object.VersionID = "v1"
+	event := &events.S3Event{Records: []events.S3EventRecord{versioned}}
+	fetcher := &mockFetcher{objects: map[string]string{"bucket/a": "current", "bucket/a@v1": "v1"}}
+	mockHandler := &mockHandler{}
+	wrappedHandler := s3event.WrapHandler(mockHandler, s3event.FetchBody(fetcher), s3event.FetchMetadata(fetcher))
+
+	// when
+	err := wrappedHandler(event)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"v1"}, mockHandler.bodies)
+	assert.Equal(t, int64(2), *mockHandler.calls[0].Metadata.ContentLength)
+}
+
+func TestWrapHandler_aggregatesErrors(t *testing.T) {
+	// given
+	event := &events.S3Event{Records: []events.S3EventRecord{
+		record("ObjectCreated:Put", "bucket", "a"),
+		record("ObjectCreated:Put", "bucket", "missing"),
+		record("ObjectCreated:Put", "bucket", "%zz"),
+	}}
+	fetcher := &mockFetcher{objects: map[string]string{"bucket/a": "abc"}}
+	wrappedHandler := s3event.WrapHandler(&mockHandler{}, s3event.FetchBody(fetcher), s3event.Concurrency(1))
+
+	// when
+	err := wrappedHandler(event)
+
+	// then
+	handlerErr, ok := err.(*s3event.HandlerError)
+	assert.True(t, ok)
+	assert.Equal(t, 3, handlerErr.Total)
+	assert.Len(t, handlerErr.Records, 2)
+	assert.Contains(t, err.Error(), "2 of 3 s3 records failed")
+}
+
+func TestWrapHandler_handlerError(t *testing.T) {
+	// given
+	event := &events.S3Event{Records: []events.S3EventRecord{
+		record("ObjectCreated:Put", "bucket", "a"),
+	}}
+	handlerErr := errors.New("processing failed")
+	wrappedHandler := s3event.WrapHandler(&mockHandler{error: handlerErr})
+
+	// when
+	err := wrappedHandler(event)
+
+	// then
+	assert.Error(t, err)
+	assert.Equal(t, handlerErr, errors.Cause(err.(*s3event.HandlerError).Records[0]))
+}
@@ -0,0 +1,53 @@
+package s3event
+
+import (
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/Ryanair/goaws/s3"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	ObjectCreated = "ObjectCreated"
+	ObjectRemoved = "ObjectRemoved"
+	ObjectRestore = "ObjectRestore"
+)
+
+type Record struct {
+	EventName string
+	EventTime time.Time
+	AWSRegion string
+	Bucket    string
+	Key       string
+	Size      int64
+	ETag      string
+	VersionID string
+	Sequencer string
+	// Body is set only when FetchBody option is used, it is closed once the handler returns
+	Body io.ReadCloser
+	// Metadata is set only when FetchMetadata option is used
+	Metadata *s3.HeadObjectResult
+}
+
+// NewRecord converts event record into Record, object key arrives URL encoded in S3 notifications and is decoded here.
+func NewRecord(event *events.S3EventRecord) (*Record, error) {
+	key, err := url.QueryUnescape(event.S3.Object.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Record{
+		EventName: event.EventName,
+		EventTime: event.EventTime,
+		AWSRegion: event.AWSRegion,
+		Bucket:    event.S3.Bucket.Name,
+		Key:       key,
+		Size:      event.S3.Object.Size,
+		ETag:      event.S3.Object.ETag,
+		VersionID: event.S3.Object.VersionID,
+		Sequencer: event.S3.Object.Sequencer,
+	}, nil
+}
@@ -0,0 +1,20 @@
+package s3
+
+import (
+	"io"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// ObjectAPI is the subset of Client operating on objects, it allows replacing Client with s3test.Fake in tests.
+type ObjectAPI interface {
+	PutObject(bucket, key string, body io.ReadSeeker, options ...func(*s3.PutObjectInput)) error
+	GetObject(bucket, key string, options ...func(*s3.GetObjectInput)) (io.ReadCloser, error)
+	HeadObject(bucket, key string, options ...func(*s3.HeadObjectInput)) (*HeadObjectResult, error)
+	GetObjectMetadata(bucket, key string) (map[string]*string, error)
+	DeleteObject(bucket, key string, options ...func(*s3.DeleteObjectInput)) error
+	ListObjects(bucket, prefix string) ([]*ObjectSummary, error)
+	CopyObject(srcBucket, srcKey, dstBucket, dstKey string, options ...func(*s3.CopyObjectInput)) error
+}
+
+var _ ObjectAPI = (*Client)(nil)
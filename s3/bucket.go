@@ -0,0 +1,177 @@
+package s3
+
+import (
+	"github.com/Ryanair/goaws/internal"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+const (
+	bucketRegionHeader = "X-Amz-Bucket-Region"
+	maxDeleteObjects   = 1000
+)
+
+type HeadBucketResult struct {
+	Region *string
+}
+
+func (c *Client) CreateBucket(bucket string, options ...func(*s3.CreateBucketInput)) error {
+	input := &s3.CreateBucketInput{
+		Bucket: aws.String(bucket),
+	}
+	for _, opt := range options {
+		opt(input)
+	}
+
+	if _, err := c.s3.CreateBucket(input); err != nil {
+		return wrapErr(err, "create bucket failed")
+	}
+
+	return nil
+}
+
+// DeleteBucket deletes the bucket, when emptyFirst is set all objects and their versions are removed beforehand.
+func (c *Client) DeleteBucket(bucket string, emptyFirst bool) error {
+	if emptyFirst {
+		if err := c.EmptyBucket(bucket); err != nil {
+			return err
+		}
+	}
+
+	if _, err := c.s3.DeleteBucket(&s3.DeleteBucketInput{
+		Bucket: aws.String(bucket),
+	}); err != nil {
+		return wrapErr(err, "delete bucket failed")
+	}
+
+	return nil
+}
+
+// EmptyBucket deletes every object version and delete marker from the bucket.
+func (c *Client) EmptyBucket(bucket string) error {
+	var deleteErr error
+	err := c.s3.ListObjectVersionsPages(&s3.ListObjectVersionsInput{
+		Bucket: aws.String(bucket),
+	}, func(out *s3.ListObjectVersionsOutput, lastPage bool) bool {
+		objects := make([]*s3.ObjectIdentifier, 0, len(out.Versions)+len(out.DeleteMarkers))
+		for _, v := range out.Versions {
+			objects = append(objects, &s3.ObjectIdentifier{Key: v.Key, VersionId: v.VersionId})
+		}
+		for _, m := range out.DeleteMarkers {
+			objects = append(objects, &s3.ObjectIdentifier{Key: m.Key, VersionId: m.VersionId})
+		}
+
+		deleteErr = c.deleteObjects(bucket, objects)
+		return deleteErr == nil
+	})
+	if err != nil {
+		return wrapErr(err, "empty bucket list object versions failed")
+	}
+
+	return deleteErr
+}
+
+func (c *Client) BucketExists(bucket string) (bool, error) {
+	if _, err := c.s3.HeadBucket(&s3.HeadBucketInput{
+		Bucket: aws.String(bucket),
+	}); err != nil {
+		if isErrCode(err, ErrCodeNotFound, s3.ErrCodeNoSuchBucket) {
+			return false, nil
+		}
+		return false, wrapErr(err, "bucket exists check failed")
+	}
+
+	return true, nil
+}
+
+func isErrCode(err error, codes ...string) bool {
+	aerr, ok := err.(awserr.Error)
+	return ok && internal.AnyEquals(aerr.Code(), codes...)
+}
+
+func (c *Client) HeadBucket(bucket string) (*HeadBucketResult, error) {
+	req, _ := c.s3.HeadBucketRequest(&s3.HeadBucketInput{
+		Bucket: aws.String(bucket),
+	})
+	if err := req.Send(); err != nil {
+		return nil, wrapErr(err, "head bucket failed")
+	}
+
+	result := &HeadBucketResult{}
+	if region := req.HTTPResponse.Header.Get(bucketRegionHeader); region != "" {
+		result.Region = aws.String(region)
+	}
+	return result, nil
+}
+
+// GetBucketVersioning returns versioning status of the bucket, empty for buckets which never had versioning enabled.
+func (c *Client) GetBucketVersioning(bucket string) (string, error) {
+	out, err := c.s3.GetBucketVersioning(&s3.GetBucketVersioningInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		return "", wrapErr(err, "get bucket versioning failed")
+	}
+
+	return aws.StringValue(out.Status), nil
+}
+
+func (c *Client) SetBucketVersioning(bucket string, enabled bool) error {
+	status := s3.BucketVersioningStatusSuspended
+	if enabled {
+		status = s3.BucketVersioningStatusEnabled
+	}
+
+	if _, err := c.s3.PutBucketVersioning(&s3.PutBucketVersioningInput{
+		Bucket: aws.String(bucket),
+		VersioningConfiguration: &s3.VersioningConfiguration{
+			Status: aws.String(status),
+		},
+	}); err != nil {
+		return wrapErr(err, "set bucket versioning failed")
+	}
+
+	return nil
+}
+
+func BucketRegion(region string) func(*s3.CreateBucketInput) {
+	return func(in *s3.CreateBucketInput) {
+		in.CreateBucketConfiguration = &s3.CreateBucketConfiguration{
+			LocationConstraint: aws.String(region),
+		}
+	}
+}
+
+func BucketACL(acl string) func(*s3.CreateBucketInput) {
+	return func(in *s3.CreateBucketInput) {
+		in.ACL = aws.String(acl)
+	}
+}
+
+func (c *Client) deleteObjects(bucket string, objects []*s3.ObjectIdentifier) error {
+	for len(objects) > 0 {
+		n := len(objects)
+		if n > maxDeleteObjects {
+			n = maxDeleteObjects
+		}
+
+		out, err := c.s3.DeleteObjects(&s3.DeleteObjectsInput{
+			Bucket: aws.String(bucket),
+			Delete: &s3.Delete{Objects: objects[:n], Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			return wrapErr(err, "delete objects failed")
+		}
+		if len(out.Errors) > 0 {
+			e := out.Errors[0]
+			return wrapErr(awserr.New(aws.StringValue(e.Code), aws.StringValue(e.Message), nil),
+				"delete object "+aws.StringValue(e.Key)+" failed")
+		}
+
+		objects = objects[n:]
+	}
+
+	return nil
+}
@@ -0,0 +1,365 @@
+package s3
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+)
+
+const (
+	errCodeNoSuchLifecycleConfiguration = "NoSuchLifecycleConfiguration"
+	errCodeNoSuchCORSConfiguration      = "NoSuchCORSConfiguration"
+
+	NotificationTopic  = "Topic"
+	NotificationQueue  = "Queue"
+	NotificationLambda = "Lambda"
+)
+
+type LifecycleRule struct {
+	ID                                 string
+	Prefix                             string
+	Tags                               map[string]string
+	Enabled                            bool
+	ExpirationDays                     int64
+	NoncurrentVersionExpirationDays    int64
+	AbortIncompleteMultipartUploadDays int64
+	Transitions                        []LifecycleTransition
+	NoncurrentVersionTransitions       []LifecycleTransition
+}
+
+type LifecycleTransition struct {
+	Days         int64
+	StorageClass string
+}
+
+type CORSRule struct {
+	AllowedHeaders []string
+	AllowedMethods []string
+	AllowedOrigins []string
+	ExposeHeaders  []string
+	MaxAgeSeconds  int64
+}
+
+// Notification describes a single bucket event destination, Type is one of NotificationTopic, NotificationQueue or NotificationLambda.
+type Notification struct {
+	ID     string
+	Type   string
+	ARN    string
+	Events []string
+	Prefix string
+	Suffix string
+}
+
+func (c *Client) GetBucketLifecycle(bucket string) ([]LifecycleRule, error) {
+	out, err := c.s3.GetBucketLifecycleConfiguration(&s3.GetBucketLifecycleConfigurationInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		if isErrCode(err, errCodeNoSuchLifecycleConfiguration) {
+			return []LifecycleRule{}, nil
+		}
+		return nil, wrapErr(err, "get bucket lifecycle failed")
+	}
+
+	rules := make([]LifecycleRule, 0, len(out.Rules))
+	for _, r := range out.Rules {
+		rules = append(rules, fromLifecycleRule(r))
+	}
+	return rules, nil
+}
+
+func (c *Client) PutBucketLifecycle(bucket string, rules []LifecycleRule) error {
+	sdkRules := make([]*s3.LifecycleRule, 0, len(rules))
+	for _, r := range rules {
+		sdkRules = append(sdkRules, toLifecycleRule(r))
+	}
+
+	if _, err := c.s3.PutBucketLifecycleConfiguration(&s3.PutBucketLifecycleConfigurationInput{
+		Bucket:                 aws.String(bucket),
+		LifecycleConfiguration: &s3.BucketLifecycleConfiguration{Rules: sdkRules},
+	}); err != nil {
+		return wrapErr(err, "put bucket lifecycle failed")
+	}
+
+	return nil
+}
+
+func (c *Client) DeleteBucketLifecycle(bucket string) error {
+	if _, err := c.s3.DeleteBucketLifecycle(&s3.DeleteBucketLifecycleInput{
+		Bucket: aws.String(bucket),
+	}); err != nil {
+		return wrapErr(err, "delete bucket lifecycle failed")
+	}
+
+	return nil
+}
+
+func (c *Client) GetBucketCORS(bucket string) ([]CORSRule, error) {
+	out, err := c.s3.GetBucketCors(&s3.GetBucketCorsInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		if isErrCode(err, errCodeNoSuchCORSConfiguration) {
+			return []CORSRule{}, nil
+		}
+		return nil, wrapErr(err, "get bucket cors failed")
+	}
+
+	rules := make([]CORSRule, 0, len(out.CORSRules))
+	for _, r := range out.CORSRules {
+		rules = append(rules, fromCORSRule(r))
+	}
+	return rules, nil
+}
+
+func (c *Client) PutBucketCORS(bucket string, rules []CORSRule) error {
+	sdkRules := make([]*s3.CORSRule, 0, len(rules))
+	for _, r := range rules {
+		sdkRules = append(sdkRules, toCORSRule(r))
+	}
+
+	if _, err := c.s3.PutBucketCors(&s3.PutBucketCorsInput{
+		Bucket:            aws.String(bucket),
+		CORSConfiguration: &s3.CORSConfiguration{CORSRules: sdkRules},
+	}); err != nil {
+		return wrapErr(err, "put bucket cors failed")
+	}
+
+	return nil
+}
+
+func (c *Client) DeleteBucketCORS(bucket string) error {
+	if _, err := c.s3.DeleteBucketCors(&s3.DeleteBucketCorsInput{
+		Bucket: aws.String(bucket),
+	}); err != nil {
+		return wrapErr(err, "delete bucket cors failed")
+	}
+
+	return nil
+}
+
+func (c *Client) GetBucketNotifications(bucket string) ([]Notification, error) {
+	out, err := c.s3.GetBucketNotificationConfiguration(&s3.GetBucketNotificationConfigurationRequest{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		return nil, wrapErr(err, "get bucket notifications failed")
+	}
+
+	notifications := make([]Notification, 0)
+	for _, t := range out.TopicConfigurations {
+		notifications = append(notifications, fromNotification(NotificationTopic, t.Id, t.TopicArn, t.Events, t.Filter))
+	}
+	for _, q := range out.QueueConfigurations {
+		notifications = append(notifications, fromNotification(NotificationQueue, q.Id, q.QueueArn, q.Events, q.Filter))
+	}
+	for _, l := range out.LambdaFunctionConfigurations {
+		notifications = append(notifications, fromNotification(NotificationLambda, l.Id, l.LambdaFunctionArn, l.Events, l.Filter))
+	}
+	return notifications, nil
+}
+
+// PutBucketNotifications replaces all notifications of the bucket, empty list removes them.
+func (c *Client) PutBucketNotifications(bucket string, notifications []Notification) error {
+	cfg := &s3.NotificationConfiguration{}
+	for _, n := range notifications {
+		id, events, filter := toNotificationFields(n)
+		switch n.Type {
+		case NotificationTopic:
+			cfg.TopicConfigurations = append(cfg.TopicConfigurations, &s3.TopicConfiguration{
+				Id: id, Events: events, Filter: filter, TopicArn: aws.String(n.ARN),
+			})
+		case NotificationQueue:
+			cfg.QueueConfigurations = append(cfg.QueueConfigurations, &s3.QueueConfiguration{
+				Id: id, Events: events, Filter: filter, QueueArn: aws.String(n.ARN),
+			})
+		case NotificationLambda:
+			cfg.LambdaFunctionConfigurations = append(cfg.LambdaFunctionConfigurations, &s3.LambdaFunctionConfiguration{
+				Id: id, Events: events, Filter: filter, LambdaFunctionArn: aws.String(n.ARN),
+			})
+		default:
+			return wrapErr(errors.Errorf("unknown notification type %q", n.Type), "put bucket notifications failed")
+		}
+	}
+
+	if _, err := c.s3.PutBucketNotificationConfiguration(&s3.PutBucketNotificationConfigurationInput{
+		Bucket:                    aws.String(bucket),
+		NotificationConfiguration: cfg,
+	}); err != nil {
+		return wrapErr(err, "put bucket notifications failed")
+	}
+
+	return nil
+}
+
+func fromLifecycleRule(r *s3.LifecycleRule) LifecycleRule {
+	rule := LifecycleRule{
+		ID:      aws.StringValue(r.ID),
+		Prefix:  aws.StringValue(r.Prefix),
+		Enabled: aws.StringValue(r.Status) == s3.ExpirationStatusEnabled,
+	}
+
+	if f := r.Filter; f != nil {
+		switch {
+		case f.And != nil:
+			rule.Prefix = aws.StringValue(f.And.Prefix)
+			rule.Tags = fromTagSet(f.And.Tags)
+		case f.Tag != nil:
+			rule.Tags = fromTagSet([]*s3.Tag{f.Tag})
+		default:
+			rule.Prefix = aws.StringValue(f.Prefix)
+		}
+	}
+	if r.Expiration != nil {
+		rule.ExpirationDays = aws.Int64Value(r.Expiration.Days)
+	}
+	if r.NoncurrentVersionExpiration != nil {
+		rule.NoncurrentVersionExpirationDays = aws.Int64Value(r.NoncurrentVersionExpiration.NoncurrentDays)
+	}
+	if r.AbortIncompleteMultipartUpload != nil {
+		rule.AbortIncompleteMultipartUploadDays = aws.Int64Value(r.AbortIncompleteMultipartUpload.DaysAfterInitiation)
+	}
+	for _, t := range r.Transitions {
+		rule.Transitions = append(rule.Transitions, LifecycleTransition{
+			Days:         aws.Int64Value(t.Days),
+			StorageClass: aws.StringValue(t.StorageClass),
+		})
+	}
+	for _, t := range r.NoncurrentVersionTransitions {
+		rule.NoncurrentVersionTransitions = append(rule.NoncurrentVersionTransitions, LifecycleTransition{
+			Days:         aws.Int64Value(t.NoncurrentDays),
+			StorageClass: aws.StringValue(t.StorageClass),
+		})
+	}
+
+	return rule
+}
+
+func toLifecycleRule(r LifecycleRule) *s3.LifecycleRule {
+	status := s3.ExpirationStatusDisabled
+	if r.Enabled {
+		status = s3.ExpirationStatusEnabled
+	}
+
+	rule := &s3.LifecycleRule{
+		Status: aws.String(status),
+		Filter: &s3.LifecycleRuleFilter{},
+	}
+	if r.ID != "" {
+		rule.ID = aws.String(r.ID)
+	}
+
+	switch {
+	case len(r.Tags) == 0:
+		rule.Filter.Prefix = aws.String(r.Prefix)
+	case len(r.Tags) == 1 && r.Prefix == "":
+		rule.Filter.Tag = toTagSet(r.Tags)[0]
+	default:
+		rule.Filter.And = &s3.LifecycleRuleAndOperator{
+			Tags: toTagSet(r.Tags),
+		}
+		if r.Prefix != "" {
+			rule.Filter.And.Prefix = aws.String(r.Prefix)
+		}
+	}
+
+	if r.ExpirationDays > 0 {
+		rule.Expiration = &s3.LifecycleExpiration{Days: aws.Int64(r.ExpirationDays)}
+	}
+	if r.NoncurrentVersionExpirationDays > 0 {
+		rule.NoncurrentVersionExpiration = &s3.NoncurrentVersionExpiration{
+			NoncurrentDays: aws.Int64(r.NoncurrentVersionExpirationDays),
+		}
+	}
+	if r.AbortIncompleteMultipartUploadDays > 0 {
+		rule.AbortIncompleteMultipartUpload = &s3.AbortIncompleteMultipartUpload{
+			DaysAfterInitiation: aws.Int64(r.AbortIncompleteMultipartUploadDays),
+		}
+	}
+	for _, t := range r.Transitions {
+		rule.Transitions = append(rule.Transitions, &s3.Transition{
+			Days:         aws.Int64(t.Days),
+			StorageClass: aws.String(t.StorageClass),
+		})
+	}
+	for _, t := range r.NoncurrentVersionTransitions {
+		rule.NoncurrentVersionTransitions = append(rule.NoncurrentVersionTransitions, &s3.NoncurrentVersionTransition{
+			NoncurrentDays: aws.Int64(t.Days),
+			StorageClass:   aws.String(t.StorageClass),
+		})
+	}
+
+	return rule
+}
+
+func fromNotification(notificationType string, id, arn *string, events []*string, filter *s3.NotificationConfigurationFilter) Notification {
+	n := Notification{
+		ID:     aws.StringValue(id),
+		Type:   notificationType,
+		ARN:    aws.StringValue(arn),
+		Events: aws.StringValueSlice(events),
+	}
+
+	if filter != nil && filter.Key != nil {
+		for _, rule := range filter.Key.FilterRules {
+			switch aws.StringValue(rule.Name) {
+			case s3.FilterRuleNamePrefix:
+				n.Prefix = aws.StringValue(rule.Value)
+			case s3.FilterRuleNameSuffix:
+				n.Suffix = aws.StringValue(rule.Value)
+			}
+		}
+	}
+
+	return n
+}
+
+func toNotificationFields(n Notification) (id *string, events []*string, filter *s3.NotificationConfigurationFilter) {
+	if n.ID != "" {
+		id = aws.String(n.ID)
+	}
+
+	var rules []*s3.FilterRule
+	if n.Prefix != "" {
+		rules = append(rules, &s3.FilterRule{Name: aws.String(s3.FilterRuleNamePrefix), Value: aws.String(n.Prefix)})
+	}
+	if n.Suffix != "" {
+		rules = append(rules, &s3.FilterRule{Name: aws.String(s3.FilterRuleNameSuffix), Value: aws.String(n.Suffix)})
+	}
+	if len(rules) > 0 {
+		filter = &s3.NotificationConfigurationFilter{Key: &s3.KeyFilter{FilterRules: rules}}
+	}
+
+	return id, aws.StringSlice(n.Events), filter
+}
+
+func fromCORSRule(r *s3.CORSRule) CORSRule {
+	return CORSRule{
+		AllowedHeaders: stringValues(r.AllowedHeaders),
+		AllowedMethods: stringValues(r.AllowedMethods),
+		AllowedOrigins: stringValues(r.AllowedOrigins),
+		ExposeHeaders:  stringValues(r.ExposeHeaders),
+		MaxAgeSeconds:  aws.Int64Value(r.MaxAgeSeconds),
+	}
+}
+
+func toCORSRule(r CORSRule) *s3.CORSRule {
+	rule := &s3.CORSRule{
+		AllowedHeaders: aws.StringSlice(r.AllowedHeaders),
+		AllowedMethods: aws.StringSlice(r.AllowedMethods),
+		AllowedOrigins: aws.StringSlice(r.AllowedOrigins),
+		ExposeHeaders:  aws.StringSlice(r.ExposeHeaders),
+	}
+	if r.MaxAgeSeconds > 0 {
+		rule.MaxAgeSeconds = aws.Int64(r.MaxAgeSeconds)
+	}
+	return rule
+}
+
+// stringValues returns nil for empty input, unlike aws.StringValueSlice, so omitted lists read back the same as set.
+func stringValues(values []*string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	return aws.StringValueSlice(values)
+}
@@ -0,0 +1,64 @@
+// +build local ci
+
+package s3
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLifecycleRule_roundTrip(t *testing.T) {
+	// given
+	var testData = []LifecycleRule{
+		{ID: "expire-logs", Prefix: "logs/", Enabled: true, ExpirationDays: 30},
+		{ID: "archive-invoices", Tags: map[string]string{"type": "invoice"}, Enabled: true,
+			Transitions: []LifecycleTransition{{Days: 90, StorageClass: s3.TransitionStorageClassGlacier}}},
+		{ID: "cleanup", Prefix: "tmp/", Tags: map[string]string{"a": "1", "b": "2"},
+			NoncurrentVersionExpirationDays: 7, AbortIncompleteMultipartUploadDays: 1,
+			NoncurrentVersionTransitions: []LifecycleTransition{{Days: 3, StorageClass: s3.TransitionStorageClassStandardIa}}},
+	}
+
+	for _, rule := range testData {
+		// when
+		result := fromLifecycleRule(toLifecycleRule(rule))
+
+		// then
+		assert.Equal(t, rule, result)
+	}
+}
+
+func TestNotification_roundTrip(t *testing.T) {
+	// given
+	notification := Notification{
+		ID:     "uploads",
+		Type:   NotificationQueue,
+		ARN:    "arn:aws:sqs:eu-west-1:123456789012:uploads",
+		Events: []string{s3.EventS3ObjectCreated},
+		Prefix: "uploads/",
+		Suffix: ".jpg",
+	}
+
+	// when
+	id, events, filter := toNotificationFields(notification)
+	result := fromNotification(NotificationQueue, id, &notification.ARN, events, filter)
+
+	// then
+	assert.Equal(t, notification, result)
+}
+
+func TestCORSRule_roundTrip(t *testing.T) {
+	// given
+	rule := CORSRule{
+		AllowedMethods: []string{"GET", "PUT"},
+		AllowedOrigins: []string{"https://example.com"},
+		MaxAgeSeconds:  300,
+	}
+
+	// when
+	result := fromCORSRule(toCORSRule(rule))
+
+	// then
+	assert.Equal(t, rule, result)
+}
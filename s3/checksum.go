@@ -0,0 +1,228 @@
+package s3
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/pkg/errors"
+)
+
+const metaSHA256 = "Goaws-Sha256"
+
+// Checksum stores SHA-256 of the body in object metadata, GetObject verifies it while the body is read. The checksum
+// is computed by PreparePutObject once all options are applied. Content-MD5 is not needed, the SDK sends it with
+// every PutObject and UploadPart request.
+func Checksum() func(*s3.PutObjectInput) {
+	return func(in *s3.PutObjectInput) {
+		if in.Body == nil {
+			return
+		}
+
+		deferredBody(in).checksum = true
+	}
+}
+
+// putBody carries body transformations requested by options until PreparePutObject applies them.
+type putBody struct {
+	io.ReadSeeker
+	gzip     bool
+	checksum bool
+}
+
+func deferredBody(in *s3.PutObjectInput) *putBody {
+	if body, ok := in.Body.(*putBody); ok {
+		return body
+	}
+	body := &putBody{ReadSeeker: in.Body}
+	in.Body = body
+	return body
+}
+
+// PreparePutObject applies body transformations requested by options like Gzip and Checksum, compression goes first
+// so the checksum covers the stored content. It is called by PutObject once all options are set, implementations of
+// ObjectAPI have to call it as well.
+func PreparePutObject(in *s3.PutObjectInput) error {
+	body, ok := in.Body.(*putBody)
+	if !ok {
+		return nil
+	}
+	in.Body = body.ReadSeeker
+
+	sum := sha256.New()
+	switch {
+	case body.gzip:
+		compressed, err := compress(in.Body, sum)
+		if err != nil {
+			return wrapErrWithCode(err, "put object compression failed", ErrCodeMarshal)
+		}
+		in.Body = compressed
+	case body.checksum:
+		if _, err := aws.CopySeekableBody(sum, in.Body); err != nil {
+			return wrapErr(err, "put object checksum failed")
+		}
+	}
+
+	if !body.checksum {
+		return nil
+	}
+
+	if in.Metadata == nil {
+		in.Metadata = make(map[string]*string)
+	}
+	in.Metadata[metaSHA256] = aws.String(hex.EncodeToString(sum.Sum(nil)))
+	return nil
+}
+
+// UploadObject streams body to S3, splitting it into parts when it is larger than a single part. The ETag of the
+// uploaded object is compared with the one computed while streaming. ETags of objects encrypted with SSE-KMS or SSE-C
+// are not MD5 digests, so they are not verified.
+func (c *Client) UploadObject(bucket, key string, body io.Reader, options ...func(*s3manager.UploadInput)) error {
+	input := &s3manager.UploadInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	for _, opt := range options {
+		opt(input)
+	}
+
+	uploader := s3manager.NewUploaderWithClient(c.s3)
+	hasher := newPartHasher(uploader.PartSize)
+	input.Body = io.TeeReader(body, hasher)
+
+	out, err := uploader.Upload(input)
+	if err != nil {
+		return wrapErr(err, "upload object failed")
+	}
+
+	if aws.StringValue(input.ServerSideEncryption) == s3.ServerSideEncryptionAwsKms || input.SSECustomerKey != nil {
+		return nil
+	}
+
+	head := &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if out.VersionID != nil {
+		head.VersionId = out.VersionID
+	}
+	uploaded, err := c.s3.HeadObject(head)
+	if err != nil {
+		return wrapErr(err, "upload object head failed")
+	}
+
+	if etag := aws.StringValue(uploaded.ETag); !hasher.matches(etag) {
+		return wrapErrWithCode(errors.Errorf("uploaded object ETag %s differs from computed one", etag),
+			"upload object checksum verification failed", ErrCodeChecksumMismatch)
+	}
+
+	return nil
+}
+
+// verifiedBody wraps the body of a complete object in a reader which fails at EOF when the content does not match
+// SHA-256 stored in metadata or, if there is none, the ETag of an unencrypted single part upload.
+func verifiedBody(out *s3.GetObjectOutput) io.ReadCloser {
+	if out.ContentRange != nil {
+		return out.Body
+	}
+
+	if sum := metadataValue(out.Metadata, metaSHA256); sum != "" {
+		return &checksumReader{body: out.Body, hash: sha256.New(), expected: sum}
+	}
+
+	etag := strings.Trim(aws.StringValue(out.ETag), `"`)
+	if aws.StringValue(out.ServerSideEncryption) == s3.ServerSideEncryptionAwsKms || out.SSECustomerAlgorithm != nil ||
+		!isMD5Digest(etag) {
+		return out.Body
+	}
+	return &checksumReader{body: out.Body, hash: md5.New(), expected: etag}
+}
+
+type checksumReader struct {
+	body     io.ReadCloser
+	hash     hash.Hash
+	expected string
+}
+
+func (r *checksumReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	r.hash.Write(p[:n])
+
+	if err == io.EOF {
+		if actual := hex.EncodeToString(r.hash.Sum(nil)); !strings.EqualFold(actual, r.expected) {
+			return n, wrapErrWithCode(errors.Errorf("expected checksum %s, got %s", r.expected, actual),
+				"get object checksum verification failed", ErrCodeChecksumMismatch)
+		}
+	}
+	return n, err
+}
+
+func (r *checksumReader) Close() error {
+	return r.body.Close()
+}
+
+// partHasher computes both MD5 of the whole stream and multipart ETag for the given part size.
+type partHasher struct {
+	partSize int64
+	written  int64
+	whole    hash.Hash
+	part     hash.Hash
+	digests  []byte
+}
+
+func newPartHasher(partSize int64) *partHasher {
+	return &partHasher{partSize: partSize, whole: md5.New(), part: md5.New()}
+}
+
+func (h *partHasher) Write(p []byte) (int, error) {
+	h.whole.Write(p)
+
+	n := len(p)
+	for len(p) > 0 {
+		chunk := h.partSize - h.written%h.partSize
+		if int64(len(p)) < chunk {
+			chunk = int64(len(p))
+		}
+		h.part.Write(p[:chunk])
+		h.written += chunk
+		p = p[chunk:]
+
+		if h.written%h.partSize == 0 {
+			h.digests = h.part.Sum(h.digests)
+			h.part.Reset()
+		}
+	}
+	return n, nil
+}
+
+// matches compares etag with the one S3 computes, MD5 of the content for single part uploads and MD5 of part digests
+// followed by the number of parts otherwise.
+func (h *partHasher) matches(etag string) bool {
+	etag = strings.Trim(etag, `"`)
+	if !strings.Contains(etag, "-") {
+		return strings.EqualFold(etag, hex.EncodeToString(h.whole.Sum(nil)))
+	}
+
+	digests, parts := h.digests, len(h.digests)/md5.Size
+	if h.written%h.partSize != 0 {
+		digests = h.part.Sum(digests)
+		parts++
+	}
+	sum := md5.Sum(digests)
+	return strings.EqualFold(etag, fmt.Sprintf("%s-%d", hex.EncodeToString(sum[:]), parts))
+}
+
+func isMD5Digest(s string) bool {
+	if len(s) != hex.EncodedLen(md5.Size) {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
@@ -0,0 +1,151 @@
+// +build local ci
+
+package s3
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecksum_ok(t *testing.T) {
+	// given
+	body := bytes.NewReader([]byte("abc"))
+	input := &s3.PutObjectInput{Body: body}
+
+	// when
+	Checksum()(input)
+	err := PreparePutObject(input)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, body, input.Body)
+	assert.Equal(t, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", *input.Metadata[metaSHA256])
+	rest, _ := ioutil.ReadAll(body)
+	assert.Equal(t, "abc", string(rest))
+}
+
+func TestChecksum_readFailed(t *testing.T) {
+	// given
+	input := &s3.PutObjectInput{Body: failingReader{}}
+	Checksum()(input)
+
+	// when
+	err := PreparePutObject(input)
+
+	// then
+	assert.Error(t, err)
+	assert.Nil(t, input.Metadata)
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func (failingReader) Seek(int64, int) (int64, error) {
+	return 0, nil
+}
+
+func TestVerifiedBody_ok(t *testing.T) {
+	// given
+	out := &s3.GetObjectOutput{
+		Body: ioutil.NopCloser(bytes.NewReader([]byte("abc"))),
+		ETag: aws.String(`"900150983cd24fb0d6963f7d28e17f72"`),
+	}
+
+	// when
+	data, err := ioutil.ReadAll(verifiedBody(out))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", string(data))
+}
+
+func TestVerifiedBody_checksumMismatch(t *testing.T) {
+	isChecksumMismatch := func(err error) bool {
+		type checksumMismatch interface {
+			ChecksumMismatch() bool
+		}
+		e, ok := err.(checksumMismatch)
+		return ok && e.ChecksumMismatch()
+	}
+
+	testData := []*s3.GetObjectOutput{
+		{
+			Body: ioutil.NopCloser(bytes.NewReader([]byte("abd"))),
+			ETag: aws.String(`"900150983cd24fb0d6963f7d28e17f72"`),
+		},
+		{
+			Body:     ioutil.NopCloser(bytes.NewReader([]byte("abd"))),
+			ETag:     aws.String(`"6f1ed002ab5595859014ebf0951522d9-2"`),
+			Metadata: map[string]*string{"Goaws-Sha256": aws.String("ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad")},
+		},
+	}
+
+	for _, out := range testData {
+		// when
+		_, err := ioutil.ReadAll(verifiedBody(out))
+
+		// then
+		assert.True(t, isChecksumMismatch(err))
+	}
+}
+
+func TestVerifiedBody_notVerified(t *testing.T) {
+	testData := []*s3.GetObjectOutput{
+		{
+			Body:         ioutil.NopCloser(bytes.NewReader([]byte("b"))),
+			ETag:         aws.String(`"900150983cd24fb0d6963f7d28e17f72"`),
+			ContentRange: aws.String("bytes 1-1/3"),
+		},
+		{
+			Body: ioutil.NopCloser(bytes.NewReader([]byte("abd"))),
+			ETag: aws.String(`"6f1ed002ab5595859014ebf0951522d9-2"`),
+		},
+		{
+			Body:                 ioutil.NopCloser(bytes.NewReader([]byte("abd"))),
+			ETag:                 aws.String(`"6f1ed002ab5595859014ebf0951522d9"`),
+			ServerSideEncryption: aws.String(s3.ServerSideEncryptionAwsKms),
+		},
+	}
+
+	for _, out := range testData {
+		// when
+		_, err := ioutil.ReadAll(verifiedBody(out))
+
+		// then
+		assert.NoError(t, err)
+	}
+}
+
+func TestPartHasher_matches(t *testing.T) {
+	// given
+	data := []byte("0123456789")
+	parts := [][]byte{data[:4], data[4:8], data[8:]}
+	var digests []byte
+	for _, p := range parts {
+		sum := md5.Sum(p)
+		digests = append(digests, sum[:]...)
+	}
+	sum, whole := md5.Sum(digests), md5.Sum(data)
+
+	// when
+	h := newPartHasher(4)
+	_, _ = h.Write(data[:3])
+	_, _ = h.Write(data[3:])
+
+	// then
+	assert.True(t, h.matches(fmt.Sprintf(`"%s-3"`, hex.EncodeToString(sum[:]))))
+	assert.True(t, h.matches(hex.EncodeToString(whole[:])))
+	assert.False(t, h.matches(fmt.Sprintf(`"%s-2"`, hex.EncodeToString(sum[:]))))
+}
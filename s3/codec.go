@@ -0,0 +1,167 @@
+package s3
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+const (
+	contentTypeJSON = "application/json"
+	contentTypeCSV  = "text/csv"
+	encodingGzip    = "gzip"
+)
+
+// PutJSON stores v encoded as JSON, options may override the Content-Type.
+func (c *Client) PutJSON(bucket, key string, v interface{}, options ...func(*s3.PutObjectInput)) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return wrapErrWithCode(err, "put json marshal failed", ErrCodeMarshal)
+	}
+
+	return c.putEncoded(bucket, key, data, contentTypeJSON, options, "put json failed")
+}
+
+// GetJSON decodes JSON object into v, gzip encoded objects are decompressed.
+func (c *Client) GetJSON(bucket, key string, v interface{}, options ...func(*s3.GetObjectInput)) error {
+	body, err := c.getDecoded(bucket, key, options, "get json failed")
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if err := json.NewDecoder(body).Decode(v); err != nil {
+		return decodeErr(err, "get json unmarshal failed")
+	}
+	// read till EOF so the checksum is verified
+	if _, err := io.Copy(ioutil.Discard, body); err != nil {
+		return decodeErr(err, "get json unmarshal failed")
+	}
+
+	return nil
+}
+
+// PutCSV stores records encoded as CSV, options may override the Content-Type.
+func (c *Client) PutCSV(bucket, key string, records [][]string, options ...func(*s3.PutObjectInput)) error {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(records); err != nil {
+		return wrapErrWithCode(err, "put csv marshal failed", ErrCodeMarshal)
+	}
+
+	return c.putEncoded(bucket, key, buf.Bytes(), contentTypeCSV, options, "put csv failed")
+}
+
+// GetCSV decodes CSV object into records, gzip encoded objects are decompressed.
+func (c *Client) GetCSV(bucket, key string, options ...func(*s3.GetObjectInput)) ([][]string, error) {
+	body, err := c.getDecoded(bucket, key, options, "get csv failed")
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	records, err := csv.NewReader(body).ReadAll()
+	if err != nil {
+		return nil, decodeErr(err, "get csv unmarshal failed")
+	}
+
+	return records, nil
+}
+
+// Gzip compresses the body and sets Content-Encoding. The body is compressed by PreparePutObject once all options are
+// applied, so Checksum hashes the compressed content regardless of the order of options.
+func Gzip() func(*s3.PutObjectInput) {
+	return func(in *s3.PutObjectInput) {
+		if in.Body == nil {
+			return
+		}
+
+		deferredBody(in).gzip = true
+		in.ContentEncoding = aws.String(encodingGzip)
+	}
+}
+
+// compress returns gzip compressed body, the compressed content is also written to w.
+func compress(body io.ReadSeeker, w io.Writer) (io.ReadSeeker, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(io.MultiWriter(&buf, w))
+	if _, err := aws.CopySeekableBody(zw, body); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(buf.Bytes()), nil
+}
+
+func (c *Client) putEncoded(bucket, key string, data []byte, contentType string, options []func(*s3.PutObjectInput), msg string) error {
+	input := &s3.PutObjectInput{
+		Body:        bytes.NewReader(data),
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+	}
+	for _, opt := range options {
+		opt(input)
+	}
+	if err := PreparePutObject(input); err != nil {
+		return err
+	}
+
+	if _, err := c.s3.PutObject(input); err != nil {
+		return wrapErr(err, msg)
+	}
+
+	return nil
+}
+
+func (c *Client) getDecoded(bucket, key string, options []func(*s3.GetObjectInput), msg string) (io.ReadCloser, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	for _, opt := range options {
+		opt(input)
+	}
+
+	out, err := c.getObject(input, msg)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(aws.StringValue(out.ContentEncoding), encodingGzip) {
+		return out.Body, nil
+	}
+
+	zr, err := gzip.NewReader(out.Body)
+	if err != nil {
+		out.Body.Close()
+		return nil, decodeErr(err, msg)
+	}
+	return &gzipBody{Reader: zr, body: out.Body}, nil
+}
+
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (b *gzipBody) Close() error {
+	b.Reader.Close()
+	return b.body.Close()
+}
+
+// decodeErr keeps errors raised by the body, like checksum mismatch, and marks the rest as unmarshalling failures.
+func decodeErr(err error, msg string) error {
+	if e, ok := err.(Error); ok {
+		return e
+	}
+	return wrapErrWithCode(err, msg, ErrCodeUnmarshal)
+}
@@ -0,0 +1,64 @@
+// +build local ci
+
+package s3
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGzip_checksumOrder(t *testing.T) {
+	// given
+	var testData = [][]func(*s3.PutObjectInput){
+		{Gzip(), Checksum()},
+		{Checksum(), Gzip()},
+	}
+
+	for _, options := range testData {
+		input := &s3.PutObjectInput{Body: bytes.NewReader([]byte("abc"))}
+
+		// when
+		for _, opt := range options {
+			opt(input)
+		}
+		err := PreparePutObject(input)
+
+		// then
+		assert.NoError(t, err)
+		compressed, _ := ioutil.ReadAll(input.Body)
+		sum := sha256.Sum256(compressed)
+		assert.Equal(t, hex.EncodeToString(sum[:]), *input.Metadata[metaSHA256])
+		assert.Equal(t, encodingGzip, *input.ContentEncoding)
+
+		zr, _ := gzip.NewReader(bytes.NewReader(compressed))
+		plain, _ := ioutil.ReadAll(zr)
+		assert.Equal(t, "abc", string(plain))
+	}
+}
+
+func TestGzip_readFailed(t *testing.T) {
+	// given
+	input := &s3.PutObjectInput{Body: failingReader{}}
+	Gzip()(input)
+
+	// when
+	err := PreparePutObject(input)
+
+	// then
+	isMarshallingFailed := func(err error) bool {
+		type marshallingFailed interface {
+			MarshallingFailed() bool
+		}
+		e, ok := err.(marshallingFailed)
+		return ok && e.MarshallingFailed()
+	}
+
+	assert.True(t, isMarshallingFailed(err))
+}
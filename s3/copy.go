@@ -0,0 +1,257 @@
+package s3
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+const (
+	// maxCopyObjectSize is the largest object S3 can copy with a single CopyObject request.
+	maxCopyObjectSize = 5 * 1024 * 1024 * 1024
+	copyPartSize      = 512 * 1024 * 1024
+	maxUploadParts    = 10000
+	copyConcurrency   = 5
+)
+
+// CopyObject performs a server-side copy of srcBucket/srcKey to dstBucket/dstKey.
+// Objects larger than 5GB are copied part by part with UploadPartCopy.
+// Metadata and tags of the source are preserved unless ReplaceMetadata or ReplaceTags option is given,
+// Content-Type of the source is kept with replaced metadata unless set explicitly.
+func (c *Client) CopyObject(srcBucket, srcKey, dstBucket, dstKey string, options ...func(*s3.CopyObjectInput)) error {
+	input := &s3.CopyObjectInput{
+		Bucket:     aws.String(dstBucket),
+		Key:        aws.String(dstKey),
+		CopySource: aws.String(copySource(srcBucket, srcKey)),
+	}
+	for _, opt := range options {
+		opt(input)
+	}
+
+	head, err := c.s3.HeadObject(&s3.HeadObjectInput{
+		Bucket:    aws.String(srcBucket),
+		Key:       aws.String(srcKey),
+		VersionId: copySourceVersionID(input.CopySource),
+	})
+	if err != nil {
+		return wrapErr(err, "copy object head source failed")
+	}
+	if aws.StringValue(input.MetadataDirective) == s3.MetadataDirectiveReplace && input.ContentType == nil {
+		input.ContentType = head.ContentType
+	}
+
+	if aws.Int64Value(head.ContentLength) <= maxCopyObjectSize {
+		if _, err := c.s3.CopyObject(input); err != nil {
+			return wrapErr(err, "copy object failed")
+		}
+		return nil
+	}
+
+	return c.multipartCopy(srcBucket, srcKey, head, input)
+}
+
+// MoveObject copies srcBucket/srcKey to dstBucket/dstKey and deletes the source once the copy succeeded.
+// With CopySourceVersionID option only the copied version of the source is deleted.
+func (c *Client) MoveObject(srcBucket, srcKey, dstBucket, dstKey string, options ...func(*s3.CopyObjectInput)) error {
+	if err := c.CopyObject(srcBucket, srcKey, dstBucket, dstKey, options...); err != nil {
+		return err
+	}
+
+	input := &s3.CopyObjectInput{CopySource: aws.String(copySource(srcBucket, srcKey))}
+	for _, opt := range options {
+		opt(input)
+	}
+	if v := copySourceVersionID(input.CopySource); v != nil {
+		return c.DeleteObject(srcBucket, srcKey, DeleteVersionID(*v))
+	}
+
+	return c.DeleteObject(srcBucket, srcKey)
+}
+
+func ReplaceMetadata(meta map[string]*string) func(*s3.CopyObjectInput) {
+	return func(in *s3.CopyObjectInput) {
+		in.Metadata = meta
+		in.MetadataDirective = aws.String(s3.MetadataDirectiveReplace)
+	}
+}
+
+func ReplaceTags(tags map[string]string) func(*s3.CopyObjectInput) {
+	return func(in *s3.CopyObjectInput) {
+		in.Tagging = aws.String(encodeTags(tags))
+		in.TaggingDirective = aws.String(s3.TaggingDirectiveReplace)
+	}
+}
+
+func (c *Client) multipartCopy(srcBucket, srcKey string, head *s3.HeadObjectOutput, in *s3.CopyObjectInput) error {
+	create := &s3.CreateMultipartUploadInput{
+		Bucket:                  in.Bucket,
+		Key:                     in.Key,
+		ACL:                     in.ACL,
+		StorageClass:            in.StorageClass,
+		ServerSideEncryption:    in.ServerSideEncryption,
+		SSEKMSKeyId:             in.SSEKMSKeyId,
+		BucketKeyEnabled:        in.BucketKeyEnabled,
+		SSECustomerAlgorithm:    in.SSECustomerAlgorithm,
+		SSECustomerKey:          in.SSECustomerKey,
+		SSECustomerKeyMD5:       in.SSECustomerKeyMD5,
+		WebsiteRedirectLocation: in.WebsiteRedirectLocation,
+	}
+
+	if aws.StringValue(in.MetadataDirective) == s3.MetadataDirectiveReplace {
+		create.Metadata = in.Metadata
+		create.ContentType = in.ContentType
+		create.ContentEncoding = in.ContentEncoding
+		create.ContentDisposition = in.ContentDisposition
+		create.ContentLanguage = in.ContentLanguage
+		create.CacheControl = in.CacheControl
+		create.Expires = in.Expires
+	} else {
+		create.Metadata = head.Metadata
+		create.ContentType = head.ContentType
+		create.ContentEncoding = head.ContentEncoding
+		create.ContentDisposition = head.ContentDisposition
+		create.ContentLanguage = head.ContentLanguage
+		create.CacheControl = head.CacheControl
+	}
+
+	if aws.StringValue(in.TaggingDirective) == s3.TaggingDirectiveReplace {
+		create.Tagging = in.Tagging
+	} else {
+		tagging, err := c.s3.GetObjectTagging(&s3.GetObjectTaggingInput{
+			Bucket:    aws.String(srcBucket),
+			Key:       aws.String(srcKey),
+			VersionId: copySourceVersionID(in.CopySource),
+		})
+		if err != nil {
+			return wrapErr(err, "multipart copy get source tags failed")
+		}
+		if len(tagging.TagSet) > 0 {
+			create.Tagging = aws.String(encodeTags(fromTagSet(tagging.TagSet)))
+		}
+	}
+
+	upload, err := c.s3.CreateMultipartUpload(create)
+	if err != nil {
+		return wrapErr(err, "multipart copy create upload failed")
+	}
+
+	parts, err := c.uploadPartsCopy(upload, in, aws.Int64Value(head.ContentLength))
+	if err != nil {
+		c.abortMultipartUpload(upload)
+		return err
+	}
+
+	if _, err := c.s3.CompleteMultipartUpload(&s3.CompleteMultipartUploadInput{
+		Bucket:          upload.Bucket,
+		Key:             upload.Key,
+		UploadId:        upload.UploadId,
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: parts},
+	}); err != nil {
+		c.abortMultipartUpload(upload)
+		return wrapErr(err, "multipart copy complete upload failed")
+	}
+
+	return nil
+}
+
+func (c *Client) uploadPartsCopy(upload *s3.CreateMultipartUploadOutput, in *s3.CopyObjectInput, size int64) ([]*s3.CompletedPart, error) {
+	ranges := copyRanges(size, copyPartSize)
+	parts := make([]*s3.CompletedPart, len(ranges))
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		copyErr error
+		sem     = make(chan struct{}, copyConcurrency)
+	)
+	for i, r := range ranges {
+		sem <- struct{}{}
+		mu.Lock()
+		failed := copyErr != nil
+		mu.Unlock()
+		if failed {
+			<-sem
+			break
+		}
+
+		wg.Add(1)
+		go func(partNumber int64, byteRange string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			out, err := c.s3.UploadPartCopy(&s3.UploadPartCopyInput{
+				Bucket:                         upload.Bucket,
+				Key:                            upload.Key,
+				UploadId:                       upload.UploadId,
+				PartNumber:                     aws.Int64(partNumber),
+				CopySource:                     in.CopySource,
+				CopySourceRange:                aws.String(byteRange),
+				SSECustomerAlgorithm:           in.SSECustomerAlgorithm,
+				SSECustomerKey:                 in.SSECustomerKey,
+				SSECustomerKeyMD5:              in.SSECustomerKeyMD5,
+				CopySourceSSECustomerAlgorithm: in.CopySourceSSECustomerAlgorithm,
+				CopySourceSSECustomerKey:       in.CopySourceSSECustomerKey,
+				CopySourceSSECustomerKeyMD5:    in.CopySourceSSECustomerKeyMD5,
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if copyErr == nil {
+					copyErr = wrapErr(err, fmt.Sprintf("multipart copy of part %d failed", partNumber))
+				}
+				return
+			}
+			parts[partNumber-1] = &s3.CompletedPart{
+				ETag:       out.CopyPartResult.ETag,
+				PartNumber: aws.Int64(partNumber),
+			}
+		}(int64(i+1), r)
+	}
+	wg.Wait()
+
+	if copyErr != nil {
+		return nil, copyErr
+	}
+	return parts, nil
+}
+
+func (c *Client) abortMultipartUpload(upload *s3.CreateMultipartUploadOutput) {
+	_, _ = c.s3.AbortMultipartUpload(&s3.AbortMultipartUploadInput{
+		Bucket:   upload.Bucket,
+		Key:      upload.Key,
+		UploadId: upload.UploadId,
+	})
+}
+
+// copyRanges splits an object of given size into CopySourceRange values,
+// growing the part size when needed to stay within the S3 parts limit.
+func copyRanges(size, partSize int64) []string {
+	if size/partSize >= maxUploadParts {
+		partSize = size/maxUploadParts + 1
+	}
+
+	var ranges []string
+	for start := int64(0); start < size; start += partSize {
+		end := start + partSize - 1
+		if end >= size {
+			end = size - 1
+		}
+		ranges = append(ranges, fmt.Sprintf("bytes=%d-%d", start, end))
+	}
+	return ranges
+}
+
+func copySource(bucket, key string) string {
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return bucket + "/" + strings.Join(segments, "/")
+}
@@ -0,0 +1,266 @@
+package s3
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+)
+
+const (
+	metaEncryptedKey = "Goaws-Cek"
+	metaIV           = "Goaws-Iv"
+	metaCEKAlgorithm = "Goaws-Cek-Alg"
+
+	cekAlgorithmAESGCM = "AES/GCM/NoPadding"
+	dataKeySize        = 32
+)
+
+func SSES3() func(*s3.PutObjectInput) {
+	return func(in *s3.PutObjectInput) {
+		in.ServerSideEncryption = aws.String(s3.ServerSideEncryptionAes256)
+	}
+}
+
+func SSEKMS(keyID string, bucketKey bool) func(*s3.PutObjectInput) {
+	return func(in *s3.PutObjectInput) {
+		in.ServerSideEncryption = aws.String(s3.ServerSideEncryptionAwsKms)
+		if keyID != "" {
+			in.SSEKMSKeyId = aws.String(keyID)
+		}
+		if bucketKey {
+			in.BucketKeyEnabled = aws.Bool(true)
+		}
+	}
+}
+
+// SSECustomerKey encrypts the object with customer provided 256-bit key, the same key has to be given to read it back.
+func SSECustomerKey(key []byte) func(*s3.PutObjectInput) {
+	return func(in *s3.PutObjectInput) {
+		in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5 = sseCustomerKey(key)
+	}
+}
+
+func GetSSECustomerKey(key []byte) func(*s3.GetObjectInput) {
+	return func(in *s3.GetObjectInput) {
+		in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5 = sseCustomerKey(key)
+	}
+}
+
+func HeadSSECustomerKey(key []byte) func(*s3.HeadObjectInput) {
+	return func(in *s3.HeadObjectInput) {
+		in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5 = sseCustomerKey(key)
+	}
+}
+
+func CopySSECustomerKey(key []byte) func(*s3.CopyObjectInput) {
+	return func(in *s3.CopyObjectInput) {
+		in.SSECustomerAlgorithm, in.SSECustomerKey, in.SSECustomerKeyMD5 = sseCustomerKey(key)
+	}
+}
+
+func CopySourceSSECustomerKey(key []byte) func(*s3.CopyObjectInput) {
+	return func(in *s3.CopyObjectInput) {
+		in.CopySourceSSECustomerAlgorithm, in.CopySourceSSECustomerKey, in.CopySourceSSECustomerKeyMD5 = sseCustomerKey(key)
+	}
+}
+
+func sseCustomerKey(key []byte) (algorithm, customerKey, keyMD5 *string) {
+	sum := md5.Sum(key)
+	return aws.String(s3.ServerSideEncryptionAes256),
+		aws.String(string(key)),
+		aws.String(base64.StdEncoding.EncodeToString(sum[:]))
+}
+
+// KeyProvider supplies data keys for client-side envelope encryption.
+// GenerateDataKey returns a new plaintext data key together with its encrypted form,
+// which is stored next to the object and given back to DecryptDataKey when the object is read.
+type KeyProvider interface {
+	GenerateDataKey() (plaintext, encrypted []byte, err error)
+	DecryptDataKey(encrypted []byte) ([]byte, error)
+}
+
+// EncryptionClient encrypts objects with AES-GCM before they are sent to S3.
+// Objects are kept in memory while being encrypted or decrypted. Bucket and key are authenticated together with
+// the content, so the ciphertext cannot be moved to another key and decrypted there.
+type EncryptionClient struct {
+	cli  *Client
+	keys KeyProvider
+}
+
+func NewEncryptionClient(cli *Client, keys KeyProvider) *EncryptionClient {
+	return &EncryptionClient{cli: cli, keys: keys}
+}
+
+func (c *EncryptionClient) PutObject(bucket, key string, body io.ReadSeeker, options ...func(*s3.PutObjectInput)) error {
+	plaintext, err := ioutil.ReadAll(body)
+	if err != nil {
+		return wrapErrWithCode(err, "read object to encrypt failed", ErrCodeEncryption)
+	}
+
+	plainKey, encryptedKey, err := c.keys.GenerateDataKey()
+	if err != nil {
+		return wrapErrWithCode(err, "generate data key failed", ErrCodeEncryption)
+	}
+
+	ciphertext, iv, err := seal(plainKey, plaintext, objectAAD(bucket, key))
+	if err != nil {
+		return wrapErrWithCode(err, "encrypt object failed", ErrCodeEncryption)
+	}
+
+	envelope := func(in *s3.PutObjectInput) {
+		if in.Metadata == nil {
+			in.Metadata = make(map[string]*string)
+		}
+		in.Metadata[metaEncryptedKey] = aws.String(base64.StdEncoding.EncodeToString(encryptedKey))
+		in.Metadata[metaIV] = aws.String(base64.StdEncoding.EncodeToString(iv))
+		in.Metadata[metaCEKAlgorithm] = aws.String(cekAlgorithmAESGCM)
+	}
+
+	return c.cli.PutObject(bucket, key, bytes.NewReader(ciphertext), append(options, envelope)...)
+}
+
+func (c *EncryptionClient) GetObject(bucket, key string, options ...func(*s3.GetObjectInput)) (io.ReadCloser, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	for _, opt := range options {
+		opt(input)
+	}
+	if input.Range != nil || input.PartNumber != nil {
+		return nil, wrapErrWithCode(errors.New("partial content cannot be authenticated"),
+			"get encrypted object failed", ErrCodeDecryption)
+	}
+
+	out, err := c.cli.getObject(input, "get encrypted object failed")
+	if err != nil {
+		return nil, err
+	}
+	defer out.Body.Close()
+
+	if alg := metadataValue(out.Metadata, metaCEKAlgorithm); alg != cekAlgorithmAESGCM {
+		return nil, wrapErrWithCode(errors.Errorf("unsupported content encryption algorithm %q", alg),
+			"decrypt object failed", ErrCodeDecryption)
+	}
+
+	encryptedKey, err := base64.StdEncoding.DecodeString(metadataValue(out.Metadata, metaEncryptedKey))
+	if err != nil {
+		return nil, wrapErrWithCode(err, "decode data key failed", ErrCodeDecryption)
+	}
+	iv, err := base64.StdEncoding.DecodeString(metadataValue(out.Metadata, metaIV))
+	if err != nil {
+		return nil, wrapErrWithCode(err, "decode iv failed", ErrCodeDecryption)
+	}
+
+	plainKey, err := c.keys.DecryptDataKey(encryptedKey)
+	if err != nil {
+		return nil, wrapErrWithCode(err, "decrypt data key failed", ErrCodeDecryption)
+	}
+
+	ciphertext, err := ioutil.ReadAll(out.Body)
+	if err != nil {
+		return nil, wrapErr(err, "read encrypted object failed")
+	}
+
+	plaintext, err := unseal(plainKey, iv, ciphertext, objectAAD(bucket, key))
+	if err != nil {
+		return nil, wrapErrWithCode(err, "decrypt object failed", ErrCodeDecryption)
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(plaintext)), nil
+}
+
+// StaticKeyProvider wraps data keys with a single AES master key held in memory.
+type StaticKeyProvider struct {
+	masterKey []byte
+}
+
+func NewStaticKeyProvider(masterKey []byte) (*StaticKeyProvider, error) {
+	if _, err := aes.NewCipher(masterKey); err != nil {
+		return nil, wrapErrWithCode(err, "invalid master key", ErrCodeEncryption)
+	}
+	return &StaticKeyProvider{masterKey: masterKey}, nil
+}
+
+func (p *StaticKeyProvider) GenerateDataKey() (plaintext, encrypted []byte, err error) {
+	plaintext = make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, plaintext); err != nil {
+		return nil, nil, err
+	}
+
+	ciphertext, iv, err := seal(p.masterKey, plaintext, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return plaintext, append(iv, ciphertext...), nil
+}
+
+func (p *StaticKeyProvider) DecryptDataKey(encrypted []byte) ([]byte, error) {
+	gcm, err := newGCM(p.masterKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(encrypted) < gcm.NonceSize() {
+		return nil, errors.New("encrypted data key too short")
+	}
+
+	return gcm.Open(nil, encrypted[:gcm.NonceSize()], encrypted[gcm.NonceSize():], nil)
+}
+
+func seal(key, plaintext, aad []byte) (ciphertext, iv []byte, err error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	iv = make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, nil, err
+	}
+
+	return gcm.Seal(nil, iv, plaintext, aad), iv, nil
+}
+
+func unseal(key, iv, ciphertext, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != gcm.NonceSize() {
+		return nil, errors.New("invalid iv size")
+	}
+
+	return gcm.Open(nil, iv, ciphertext, aad)
+}
+
+func objectAAD(bucket, key string) []byte {
+	return []byte(bucket + "/" + key)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func metadataValue(meta map[string]*string, key string) string {
+	for k, v := range meta {
+		if strings.EqualFold(k, key) {
+			return aws.StringValue(v)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,103 @@
+// +build local ci
+
+package s3
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticKeyProvider_ok(t *testing.T) {
+	// given
+	provider, err := NewStaticKeyProvider([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("failed to create key provider: %s", err)
+	}
+
+	// when
+	plainKey, encryptedKey, genErr := provider.GenerateDataKey()
+	decryptedKey, decErr := provider.DecryptDataKey(encryptedKey)
+
+	// then
+	assert.NoError(t, genErr)
+	assert.NoError(t, decErr)
+	assert.Len(t, plainKey, dataKeySize)
+	assert.NotEqual(t, plainKey, encryptedKey)
+	assert.Equal(t, plainKey, decryptedKey)
+}
+
+func TestStaticKeyProvider_invalidMasterKey(t *testing.T) {
+	// when
+	_, err := NewStaticKeyProvider([]byte("too short"))
+
+	// then
+	isEncryptionFailed := func(err error) bool {
+		type encryptionFailed interface {
+			EncryptionFailed() bool
+		}
+		e, ok := err.(encryptionFailed)
+		return ok && e.EncryptionFailed()
+	}
+
+	assert.True(t, isEncryptionFailed(err))
+}
+
+func TestSealUnseal_ok(t *testing.T) {
+	// given
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	// when
+	ciphertext, iv, sealErr := seal(key, []byte("abc"), objectAAD("bucket", "key"))
+	plaintext, unsealErr := unseal(key, iv, ciphertext, objectAAD("bucket", "key"))
+
+	// then
+	assert.NoError(t, sealErr)
+	assert.NoError(t, unsealErr)
+	assert.Equal(t, "abc", string(plaintext))
+}
+
+func TestSealUnseal_tampered(t *testing.T) {
+	// given
+	key := []byte("0123456789abcdef0123456789abcdef")
+	ciphertext, iv, _ := seal(key, []byte("abc"), objectAAD("bucket", "key"))
+	ciphertext[0] ^= 0xff
+
+	// when
+	_, err := unseal(key, iv, ciphertext, objectAAD("bucket", "key"))
+
+	// then
+	assert.Error(t, err)
+}
+
+func TestSealUnseal_otherObject(t *testing.T) {
+	// given
+	key := []byte("0123456789abcdef0123456789abcdef")
+	ciphertext, iv, _ := seal(key, []byte("abc"), objectAAD("bucket", "key"))
+
+	// when
+	_, err := unseal(key, iv, ciphertext, objectAAD("bucket", "other-key"))
+
+	// then
+	assert.Error(t, err)
+}
+
+func TestEncryptionClient_GetObject_rangeRejected(t *testing.T) {
+	// given
+	provider, _ := NewStaticKeyProvider([]byte("0123456789abcdef0123456789abcdef"))
+	encCli := NewEncryptionClient(&Client{}, provider)
+
+	// when
+	_, err := encCli.GetObject("bucket", "key", Range(0, 1))
+
+	// then
+	isDecryptionFailed := func(err error) bool {
+		type decryptionFailed interface {
+			DecryptionFailed() bool
+		}
+		e, ok := err.(decryptionFailed)
+		return ok && e.DecryptionFailed()
+	}
+
+	assert.True(t, isDecryptionFailed(err))
+}
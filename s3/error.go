@@ -7,7 +7,24 @@ import (
 )
 
 const (
-	ErrCodeSigningURL = "SigningURLErr"
+	ErrCodeSigningURL          = "SigningURLErr"
+	ErrCodeEncryption          = "EncryptionErr"
+	ErrCodeDecryption          = "DecryptionErr"
+	ErrCodeNotModified         = "NotModified"
+	ErrCodePreconditionFailed  = "PreconditionFailed"
+	ErrCodeChecksumMismatch    = "ChecksumMismatchErr"
+	ErrCodeMarshal             = "MarshalErr"
+	ErrCodeUnmarshal           = "UnmarshalErr"
+	ErrCodeSelectIncomplete    = "SelectIncompleteErr"
+	ErrCodeInvalidPresignedURL = "InvalidPresignedURLErr"
+	ErrCodeWaitTimeout         = "WaitTimeoutErr"
+	ErrCodeUnexpectedObject    = "UnexpectedObjectErr"
+	// ErrCodeRestoreInProgress is returned by S3 when restore of the object was already requested
+	ErrCodeRestoreInProgress = "RestoreAlreadyInProgress"
+	// ErrCodeBadDigest is returned by S3 when uploaded content does not match Content-MD5
+	ErrCodeBadDigest = "BadDigest"
+	// ErrCodeNotFound is returned for HEAD requests, which carry no error body
+	ErrCodeNotFound = "NotFound"
 )
 
 type Error internal.Error
@@ -43,10 +60,62 @@ func (e Error) KeyNotFound() bool {
 func (e Error) ResourceNotFound() bool {
 	return internal.AnyEquals(e.Code,
 		s3.ErrCodeNoSuchKey,
-		s3.ErrCodeNoSuchBucket)
+		s3.ErrCodeNoSuchBucket,
+		ErrCodeNotFound)
 }
 
 func (e Error) BucketAlreadyExists() bool {
 	return internal.AnyEquals(e.Code,
 		s3.ErrCodeBucketAlreadyExists)
 }
+
+func (e Error) NotModified() bool {
+	return internal.AnyEquals(e.Code, ErrCodeNotModified)
+}
+
+func (e Error) PreconditionFailed() bool {
+	return internal.AnyEquals(e.Code, ErrCodePreconditionFailed)
+}
+
+func (e Error) EncryptionFailed() bool {
+	return internal.AnyEquals(e.Code, ErrCodeEncryption)
+}
+
+func (e Error) DecryptionFailed() bool {
+	return internal.AnyEquals(e.Code, ErrCodeDecryption)
+}
+
+func (e Error) ChecksumMismatch() bool {
+	return internal.AnyEquals(e.Code, ErrCodeChecksumMismatch, ErrCodeBadDigest)
+}
+
+func (e Error) MarshallingFailed() bool {
+	return internal.AnyEquals(e.Code, ErrCodeMarshal)
+}
+
+func (e Error) UnmarshallingFailed() bool {
+	return internal.AnyEquals(e.Code, ErrCodeUnmarshal)
+}
+
+func (e Error) SelectIncomplete() bool {
+	return internal.AnyEquals(e.Code, ErrCodeSelectIncomplete)
+}
+
+func (e Error) InvalidPresignedURL() bool {
+	return internal.AnyEquals(e.Code, ErrCodeInvalidPresignedURL)
+}
+
+func (e Error) WaitTimedOut() bool {
+	return internal.AnyEquals(e.Code, ErrCodeWaitTimeout)
+}
+
+func (e Error) UnexpectedObject() bool {
+	return internal.AnyEquals(e.Code, ErrCodeUnexpectedObject)
+}
+
+// ObjectArchived reports that the object is in an archive storage class and has to be restored before it can be read.
+func (e Error) ObjectArchived() bool {
+	return internal.AnyEquals(e.Code,
+		s3.ErrCodeInvalidObjectState,
+		s3.ErrCodeObjectNotInActiveTierError)
+}
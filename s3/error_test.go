@@ -0,0 +1,100 @@
+// +build local ci
+
+package s3
+
+import (
+	"testing"
+
+	"github.com/Ryanair/goaws/internal"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+type errorFunction func(code string) bool
+type params struct {
+	in  string
+	out bool
+}
+
+func TestErrorBehaviour(t *testing.T) {
+
+	var testData = []struct {
+		params   params
+		function errorFunction
+	}{
+		{params{s3.ErrCodeNoSuchKey, true}, func(code string) bool {
+			err := Error(internal.NewError("", code, errors.New("no such key")))
+			return err.KeyNotFound()
+		}},
+		{params{s3.ErrCodeNoSuchBucket, false}, func(code string) bool {
+			err := Error(internal.NewError("", code, errors.New("no such bucket")))
+			return err.KeyNotFound()
+		}},
+
+		{params{ErrCodeNotFound, true}, func(code string) bool {
+			err := Error(internal.NewError("", code, errors.New("not found")))
+			return err.ResourceNotFound()
+		}},
+		{params{ErrCodeNotModified, false}, func(code string) bool {
+			err := Error(internal.NewError("", code, errors.New("not modified")))
+			return err.ResourceNotFound()
+		}},
+
+		{params{ErrCodeNotModified, true}, func(code string) bool {
+			err := Error(internal.NewError("", code, errors.New("not modified")))
+			return err.NotModified()
+		}},
+		{params{ErrCodePreconditionFailed, false}, func(code string) bool {
+			err := Error(internal.NewError("", code, errors.New("precondition failed")))
+			return err.NotModified()
+		}},
+
+		{params{ErrCodePreconditionFailed, true}, func(code string) bool {
+			err := Error(internal.NewError("", code, errors.New("precondition failed")))
+			return err.PreconditionFailed()
+		}},
+		{params{ErrCodeNotModified, false}, func(code string) bool {
+			err := Error(internal.NewError("", code, errors.New("not modified")))
+			return err.PreconditionFailed()
+		}},
+		{params{ErrCodeChecksumMismatch, true}, func(code string) bool {
+			err := Error(internal.NewError("", code, errors.New("checksum mismatch")))
+			return err.ChecksumMismatch()
+		}},
+		{params{ErrCodeBadDigest, true}, func(code string) bool {
+			err := Error(internal.NewError("", code, errors.New("bad digest")))
+			return err.ChecksumMismatch()
+		}},
+		{params{s3.ErrCodeNoSuchKey, false}, func(code string) bool {
+			err := Error(internal.NewError("", code, errors.New("no such key")))
+			return err.ChecksumMismatch()
+		}},
+		{params{s3.ErrCodeInvalidObjectState, true}, func(code string) bool {
+			err := Error(internal.NewError("", code, errors.New("invalid object state")))
+			return err.ObjectArchived()
+		}},
+		{params{s3.ErrCodeNoSuchKey, false}, func(code string) bool {
+			err := Error(internal.NewError("", code, errors.New("no such key")))
+			return err.ObjectArchived()
+		}},
+		{params{ErrCodeMarshal, true}, func(code string) bool {
+			err := Error(internal.NewError("", code, errors.New("marshal failed")))
+			return err.MarshallingFailed()
+		}},
+		{params{ErrCodeUnmarshal, true}, func(code string) bool {
+			err := Error(internal.NewError("", code, errors.New("unmarshal failed")))
+			return err.UnmarshallingFailed()
+		}},
+		{params{ErrCodeMarshal, false}, func(code string) bool {
+			err := Error(internal.NewError("", code, errors.New("marshal failed")))
+			return err.UnmarshallingFailed()
+		}},
+	}
+
+	for _, data := range testData {
+		result := data.function(data.params.in)
+		assert.Equal(t, data.params.out, result)
+	}
+}
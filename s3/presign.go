@@ -0,0 +1,175 @@
+package s3
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/pkg/errors"
+)
+
+const (
+	presignAlgorithm = "AWS4-HMAC-SHA256"
+	presignTimeFmt   = "20060102T150405Z"
+
+	defaultWaitInitialInterval = 500 * time.Millisecond
+	defaultWaitMaxInterval     = 5 * time.Second
+)
+
+// PresignedURL describes a presigned URL, as generated by GeneratePutURL.
+type PresignedURL struct {
+	Bucket        string
+	Key           string
+	AccessKeyID   string
+	Region        string
+	SignedAt      time.Time
+	Expires       time.Time
+	SignedHeaders []string
+}
+
+func (p *PresignedURL) Expired() bool {
+	return time.Now().After(p.Expires)
+}
+
+// ParsePresignedURL reads bucket, key and signature details of a SigV4 presigned URL, both virtual-hosted and
+// path-style URLs are supported, the latter is assumed for custom endpoints. The signature itself is not verified.
+func ParsePresignedURL(rawURL string) (*PresignedURL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, wrapErrWithCode(err, "parse presigned url failed", ErrCodeInvalidPresignedURL)
+	}
+
+	query := u.Query()
+	if query.Get("X-Amz-Algorithm") != presignAlgorithm || query.Get("X-Amz-Signature") == "" {
+		return nil, invalidPresignedURL("missing SigV4 query parameters")
+	}
+
+	credential := strings.Split(query.Get("X-Amz-Credential"), "/")
+	if len(credential) != 5 {
+		return nil, invalidPresignedURL("malformed X-Amz-Credential")
+	}
+	signedAt, err := time.Parse(presignTimeFmt, query.Get("X-Amz-Date"))
+	if err != nil {
+		return nil, invalidPresignedURL("malformed X-Amz-Date")
+	}
+	expires, err := strconv.Atoi(query.Get("X-Amz-Expires"))
+	if err != nil {
+		return nil, invalidPresignedURL("malformed X-Amz-Expires")
+	}
+
+	bucket, key := bucketAndKey(u)
+	if bucket == "" || key == "" {
+		return nil, invalidPresignedURL("missing bucket or key")
+	}
+
+	return &PresignedURL{
+		Bucket:        bucket,
+		Key:           key,
+		AccessKeyID:   credential[0],
+		Region:        credential[2],
+		SignedAt:      signedAt,
+		Expires:       signedAt.Add(time.Duration(expires) * time.Second),
+		SignedHeaders: strings.Split(query.Get("X-Amz-SignedHeaders"), ";"),
+	}, nil
+}
+
+type WaitConfig struct {
+	contentType     *string
+	contentLength   *int64
+	initialInterval time.Duration
+	maxInterval     time.Duration
+}
+
+func WaitContentType(contentType string) func(*WaitConfig) {
+	return func(c *WaitConfig) {
+		c.contentType = aws.String(contentType)
+	}
+}
+
+func WaitContentLength(size int64) func(*WaitConfig) {
+	return func(c *WaitConfig) {
+		c.contentLength = aws.Int64(size)
+	}
+}
+
+// WaitBackoff sets the interval between checks, doubled after each check up to max.
+func WaitBackoff(initial, max time.Duration) func(*WaitConfig) {
+	return func(c *WaitConfig) {
+		if initial > 0 {
+			c.initialInterval = initial
+		}
+		if max >= initial {
+			c.maxInterval = max
+		}
+	}
+}
+
+// WaitForObject polls the object until it exists or timeout elapses, useful to confirm uploads made with presigned
+// URLs. An object which exists but does not have the expected content type or length is reported straight away.
+func (c *Client) WaitForObject(bucket, key string, timeout time.Duration, options ...func(*WaitConfig)) (*HeadObjectResult, error) {
+	cfg := &WaitConfig{initialInterval: defaultWaitInitialInterval, maxInterval: defaultWaitMaxInterval}
+	for _, opt := range options {
+		opt(cfg)
+	}
+
+	deadline := time.Now().Add(timeout)
+	interval := cfg.initialInterval
+	for {
+		head, err := c.HeadObject(bucket, key)
+		if err == nil {
+			if err := checkObject(head, cfg); err != nil {
+				return nil, err
+			}
+			return head, nil
+		}
+		if e, ok := err.(Error); !ok || !e.ResourceNotFound() {
+			return nil, err
+		}
+
+		if remaining := time.Until(deadline); remaining <= 0 {
+			return nil, wrapErrWithCode(errors.Errorf("object %s not found within %s", key, timeout),
+				"wait for object failed", ErrCodeWaitTimeout)
+		} else if interval > remaining {
+			interval = remaining
+		}
+		time.Sleep(interval)
+
+		if interval *= 2; interval > cfg.maxInterval {
+			interval = cfg.maxInterval
+		}
+	}
+}
+
+func checkObject(head *HeadObjectResult, cfg *WaitConfig) error {
+	if cfg.contentType != nil && aws.StringValue(head.ContentType) != *cfg.contentType {
+		return wrapErrWithCode(errors.Errorf("expected content type %s, got %s", *cfg.contentType, aws.StringValue(head.ContentType)),
+			"wait for object failed", ErrCodeUnexpectedObject)
+	}
+	if cfg.contentLength != nil && aws.Int64Value(head.ContentLength) != *cfg.contentLength {
+		return wrapErrWithCode(errors.Errorf("expected content length %d, got %d", *cfg.contentLength, aws.Int64Value(head.ContentLength)),
+			"wait for object failed", ErrCodeUnexpectedObject)
+	}
+	return nil
+}
+
+// bucketAndKey recognises virtual-hosted S3 host names, any other host is treated as a path-style endpoint.
+func bucketAndKey(u *url.URL) (bucket, key string) {
+	host := u.Hostname()
+	for _, marker := range []string{".s3.", ".s3-"} {
+		if i := strings.LastIndex(host, marker); i > 0 && strings.HasSuffix(host, ".amazonaws.com") {
+			return host[:i], strings.TrimPrefix(u.Path, "/")
+		}
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(u.Path, "/"), "/", 2)
+	if len(parts) != 2 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+func invalidPresignedURL(reason string) error {
+	return wrapErrWithCode(errors.New(reason), "parse presigned url failed", ErrCodeInvalidPresignedURL)
+}
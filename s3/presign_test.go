@@ -0,0 +1,60 @@
+// +build local ci
+
+package s3
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePresignedURL_ok(t *testing.T) {
+	type params struct {
+		url    string
+		bucket string
+		key    string
+	}
+
+	query := "?X-Amz-Algorithm=AWS4-HMAC-SHA256&X-Amz-Credential=AKIDEXAMPLE%2F20201120%2Feu-west-1%2Fs3%2Faws4_request" +
+		"&X-Amz-Date=20201120T101500Z&X-Amz-Expires=900&X-Amz-SignedHeaders=content-type%3Bhost&X-Amz-Signature=abc"
+	testData := []params{
+		{"https://my.bucket.s3.eu-west-1.amazonaws.com/dir/some%20key" + query, "my.bucket", "dir/some key"},
+		{"https://bucket.s3-eu-west-1.amazonaws.com/key" + query, "bucket", "key"},
+		{"https://s3.eu-west-1.amazonaws.com/bucket/dir/key" + query, "bucket", "dir/key"},
+		{"http://127.0.0.1:9000/bucket/key" + query, "bucket", "key"},
+	}
+
+	for _, data := range testData {
+		// when
+		p, err := ParsePresignedURL(data.url)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, data.bucket, p.Bucket)
+		assert.Equal(t, data.key, p.Key)
+		assert.Equal(t, "AKIDEXAMPLE", p.AccessKeyID)
+		assert.Equal(t, "eu-west-1", p.Region)
+		assert.Equal(t, time.Date(2020, 11, 20, 10, 30, 0, 0, time.UTC), p.Expires)
+		assert.Equal(t, []string{"content-type", "host"}, p.SignedHeaders)
+		assert.True(t, p.Expired())
+	}
+}
+
+func TestParsePresignedURL_invalid(t *testing.T) {
+	testData := []string{
+		"https://bucket.s3.eu-west-1.amazonaws.com/key",
+		"https://bucket.s3.eu-west-1.amazonaws.com/key?X-Amz-Algorithm=AWS4-HMAC-SHA256&X-Amz-Signature=abc",
+		"https://s3.eu-west-1.amazonaws.com/bucket?X-Amz-Algorithm=AWS4-HMAC-SHA256&X-Amz-Signature=abc" +
+			"&X-Amz-Credential=AKIDEXAMPLE%2F20201120%2Feu-west-1%2Fs3%2Faws4_request&X-Amz-Date=20201120T101500Z&X-Amz-Expires=900",
+		"://",
+	}
+
+	for _, url := range testData {
+		// when
+		_, err := ParsePresignedURL(url)
+
+		// then
+		assert.True(t, err.(Error).InvalidPresignedURL())
+	}
+}
@@ -0,0 +1,136 @@
+package s3
+
+import (
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultRestoreInitialInterval = time.Minute
+	defaultRestoreMaxInterval     = 15 * time.Minute
+)
+
+var (
+	restoreOngoingRe = regexp.MustCompile(`ongoing-request="(true|false)"`)
+	restoreExpiryRe  = regexp.MustCompile(`expiry-date="([^"]+)"`)
+)
+
+type RestoreStatus struct {
+	StorageClass *string
+	// Requested is false when no restore was started or the restored copy already expired
+	Requested  bool
+	InProgress bool
+	ExpiryDate *time.Time
+}
+
+// Available reports whether the object can be read, either because it is not archived or its restored copy is ready.
+func (s *RestoreStatus) Available() bool {
+	return !isArchiveClass(aws.StringValue(s.StorageClass)) || s.Requested && !s.InProgress
+}
+
+// RestoreObject starts restoring archived object for given number of days, a restore already in progress is not
+// reported as an error.
+func (c *Client) RestoreObject(bucket, key string, days int64, options ...func(*s3.RestoreObjectInput)) error {
+	input := &s3.RestoreObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		RestoreRequest: &s3.RestoreRequest{
+			Days: aws.Int64(days),
+		},
+	}
+	for _, opt := range options {
+		opt(input)
+	}
+
+	if _, err := c.s3.RestoreObject(input); err != nil {
+		if isErrCode(err, ErrCodeRestoreInProgress) {
+			return nil
+		}
+		return wrapErr(err, "restore object failed")
+	}
+
+	return nil
+}
+
+// RestoreTier sets retrieval tier, one of s3.Tier values.
+func RestoreTier(tier string) func(*s3.RestoreObjectInput) {
+	return func(in *s3.RestoreObjectInput) {
+		in.RestoreRequest.GlacierJobParameters = &s3.GlacierJobParameters{
+			Tier: aws.String(tier),
+		}
+	}
+}
+
+func (c *Client) GetRestoreStatus(bucket, key string) (*RestoreStatus, error) {
+	head, err := c.HeadObject(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseRestoreStatus(head.StorageClass, head.Restore), nil
+}
+
+// WaitForRestore polls restore status until the restored copy is available or timeout elapses. Only WaitBackoff
+// option applies, checks start every minute and back off up to 15 minutes by default.
+func (c *Client) WaitForRestore(bucket, key string, timeout time.Duration, options ...func(*WaitConfig)) (*RestoreStatus, error) {
+	cfg := &WaitConfig{initialInterval: defaultRestoreInitialInterval, maxInterval: defaultRestoreMaxInterval}
+	for _, opt := range options {
+		opt(cfg)
+	}
+
+	deadline := time.Now().Add(timeout)
+	interval := cfg.initialInterval
+	for {
+		status, err := c.GetRestoreStatus(bucket, key)
+		if err != nil {
+			return nil, err
+		}
+		if status.Available() {
+			return status, nil
+		}
+		if !status.Requested {
+			return nil, wrapErrWithCode(errors.Errorf("restore of %s was not requested", key),
+				"wait for restore failed", s3.ErrCodeInvalidObjectState)
+		}
+
+		if remaining := time.Until(deadline); remaining <= 0 {
+			return nil, wrapErrWithCode(errors.Errorf("object %s not restored within %s", key, timeout),
+				"wait for restore failed", ErrCodeWaitTimeout)
+		} else if interval > remaining {
+			interval = remaining
+		}
+		time.Sleep(interval)
+
+		if interval *= 2; interval > cfg.maxInterval {
+			interval = cfg.maxInterval
+		}
+	}
+}
+
+// parseRestoreStatus reads x-amz-restore header, e.g. ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT".
+func parseRestoreStatus(storageClass, restore *string) *RestoreStatus {
+	status := &RestoreStatus{StorageClass: storageClass}
+	if restore == nil {
+		return status
+	}
+
+	if m := restoreOngoingRe.FindStringSubmatch(*restore); m != nil {
+		status.Requested = true
+		status.InProgress = m[1] == "true"
+	}
+	if m := restoreExpiryRe.FindStringSubmatch(*restore); m != nil {
+		if t, err := http.ParseTime(m[1]); err == nil {
+			status.ExpiryDate = aws.Time(t)
+		}
+	}
+	return status
+}
+
+func isArchiveClass(storageClass string) bool {
+	return storageClass == s3.StorageClassGlacier || storageClass == s3.StorageClassDeepArchive
+}
@@ -0,0 +1,48 @@
+// +build local ci
+
+package s3
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRestoreStatus(t *testing.T) {
+	type params struct {
+		storageClass *string
+		restore      *string
+		requested    bool
+		inProgress   bool
+		available    bool
+	}
+
+	testData := []params{
+		{aws.String(s3.StorageClassGlacier), nil, false, false, false},
+		{aws.String(s3.StorageClassGlacier), aws.String(`ongoing-request="true"`), true, true, false},
+		{aws.String(s3.StorageClassDeepArchive), aws.String(`ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"`), true, false, true},
+		{nil, nil, false, false, true},
+	}
+
+	for _, data := range testData {
+		// when
+		status := parseRestoreStatus(data.storageClass, data.restore)
+
+		// then
+		assert.Equal(t, data.requested, status.Requested)
+		assert.Equal(t, data.inProgress, status.InProgress)
+		assert.Equal(t, data.available, status.Available())
+	}
+}
+
+func TestParseRestoreStatus_expiryDate(t *testing.T) {
+	// when
+	status := parseRestoreStatus(aws.String(s3.StorageClassGlacier),
+		aws.String(`ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"`))
+
+	// then
+	assert.Equal(t, time.Date(2012, 12, 21, 0, 0, 0, 0, time.UTC), *status.ExpiryDate)
+}
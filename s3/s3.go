@@ -1,15 +1,38 @@
 package s3
 
 import (
+	"fmt"
 	"io"
 	"time"
 
 	"github.com/Ryanair/goaws"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/s3"
 )
 
+type HeadObjectResult struct {
+	ETag            *string
+	ContentLength   *int64
+	ContentType     *string
+	ContentEncoding *string
+	CacheControl    *string
+	LastModified    *time.Time
+	VersionID       *string
+	StorageClass    *string
+	Restore         *string
+	Metadata        map[string]*string
+}
+
+type ObjectSummary struct {
+	Key          *string
+	ETag         *string
+	Size         *int64
+	LastModified *time.Time
+	StorageClass *string
+}
+
 type Client struct {
 	s3 *s3.S3
 }
@@ -29,6 +52,14 @@ func Endpoint(endpoint string) func(*s3.S3) {
 	}
 }
 
+// PathStyle makes the client address buckets as part of the path instead of the host name, required by
+// S3 compatible servers running on localhost.
+func PathStyle() func(*s3.S3) {
+	return func(s3 *s3.S3) {
+		s3.Config.S3ForcePathStyle = aws.Bool(true)
+	}
+}
+
 func (c *Client) GeneratePutURL(bucket, key, contentType string, expire time.Duration, options ...func(*s3.PutObjectInput)) (string, error) {
 	input := &s3.PutObjectInput{
 		Bucket:      &bucket,
@@ -49,29 +80,56 @@ func (c *Client) GeneratePutURL(bucket, key, contentType string, expire time.Dur
 	return url, nil
 }
 
-func (c *Client) DeleteObject(bucket, key string) error {
-	if _, err := c.s3.DeleteObject(&s3.DeleteObjectInput{
+func (c *Client) DeleteObject(bucket, key string, options ...func(*s3.DeleteObjectInput)) error {
+	input := &s3.DeleteObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
-	}); err != nil {
+	}
+	for _, opt := range options {
+		opt(input)
+	}
+
+	if _, err := c.s3.DeleteObject(input); err != nil {
 		return wrapErr(err, "delete object failed")
 	}
 
 	return nil
 }
 
-func (c *Client) GetObject(bucket, key string) (io.ReadCloser, error) {
-	out, err := c.s3.GetObject(&s3.GetObjectInput{
+func (c *Client) GetObject(bucket, key string, options ...func(*s3.GetObjectInput)) (io.ReadCloser, error) {
+	input := &s3.GetObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
-	})
+	}
+	for _, opt := range options {
+		opt(input)
+	}
+
+	out, err := c.getObject(input, "get object failed")
 	if err != nil {
-		return nil, wrapErr(err, "get object failed")
+		return nil, err
 	}
 
 	return out.Body, nil
 }
 
+// getObject returns the object with verified body. When Go transport transparently decompressed gzip encoded content,
+// the body cannot be verified and ContentEncoding is cleared.
+func (c *Client) getObject(input *s3.GetObjectInput, msg string, options ...request.Option) (*s3.GetObjectOutput, error) {
+	req, out := c.s3.GetObjectRequest(input)
+	req.ApplyOptions(options...)
+	if err := req.Send(); err != nil {
+		return nil, wrapErr(err, msg)
+	}
+
+	if req.HTTPResponse.Uncompressed {
+		out.ContentEncoding = nil
+		return out, nil
+	}
+	out.Body = verifiedBody(out)
+	return out, nil
+}
+
 func (c *Client) PutObject(bucket, key string, body io.ReadSeeker, options ...func(*s3.PutObjectInput)) error {
 	input := &s3.PutObjectInput{
 		Body:   body,
@@ -81,6 +139,9 @@ func (c *Client) PutObject(bucket, key string, body io.ReadSeeker, options ...fu
 	for _, opt := range options {
 		opt(input)
 	}
+	if err := PreparePutObject(input); err != nil {
+		return err
+	}
 
 	_, err := c.s3.PutObject(input)
 	if err != nil {
@@ -102,8 +163,97 @@ func (c *Client) GetObjectMetadata(bucket, key string) (map[string]*string, erro
 	return out.Metadata, nil
 }
 
+func (c *Client) HeadObject(bucket, key string, options ...func(*s3.HeadObjectInput)) (*HeadObjectResult, error) {
+	input := &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	for _, opt := range options {
+		opt(input)
+	}
+
+	out, err := c.s3.HeadObject(input)
+	if err != nil {
+		return nil, wrapErr(err, "head object failed")
+	}
+
+	return &HeadObjectResult{
+		ETag:            out.ETag,
+		ContentLength:   out.ContentLength,
+		ContentType:     out.ContentType,
+		ContentEncoding: out.ContentEncoding,
+		CacheControl:    out.CacheControl,
+		LastModified:    out.LastModified,
+		VersionID:       out.VersionId,
+		StorageClass:    out.StorageClass,
+		Restore:         out.Restore,
+		Metadata:        out.Metadata,
+	}, nil
+}
+
+func (c *Client) ListObjects(bucket, prefix string) ([]*ObjectSummary, error) {
+	var objects []*ObjectSummary
+	if err := c.s3.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	}, func(out *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, o := range out.Contents {
+			objects = append(objects, &ObjectSummary{
+				Key:          o.Key,
+				ETag:         o.ETag,
+				Size:         o.Size,
+				LastModified: o.LastModified,
+				StorageClass: o.StorageClass,
+			})
+		}
+		return true
+	}); err != nil {
+		return nil, wrapErr(err, "list objects failed")
+	}
+
+	return objects, nil
+}
+
 func Metadata(meta map[string]*string) func(in *s3.PutObjectInput) {
 	return func(in *s3.PutObjectInput) {
 		in.Metadata = meta
 	}
 }
+
+func IfMatch(etag string) func(*s3.GetObjectInput) {
+	return func(in *s3.GetObjectInput) {
+		in.IfMatch = aws.String(etag)
+	}
+}
+
+func IfNoneMatch(etag string) func(*s3.GetObjectInput) {
+	return func(in *s3.GetObjectInput) {
+		in.IfNoneMatch = aws.String(etag)
+	}
+}
+
+func IfModifiedSince(t time.Time) func(*s3.GetObjectInput) {
+	return func(in *s3.GetObjectInput) {
+		in.IfModifiedSince = aws.Time(t)
+	}
+}
+
+func IfUnmodifiedSince(t time.Time) func(*s3.GetObjectInput) {
+	return func(in *s3.GetObjectInput) {
+		in.IfUnmodifiedSince = aws.Time(t)
+	}
+}
+
+// Range requests bytes from start to end inclusive.
+func Range(start, end int64) func(*s3.GetObjectInput) {
+	return func(in *s3.GetObjectInput) {
+		in.Range = aws.String(fmt.Sprintf("bytes=%d-%d", start, end))
+	}
+}
+
+// RangeFrom requests bytes from start to the end of the object.
+func RangeFrom(start int64) func(*s3.GetObjectInput) {
+	return func(in *s3.GetObjectInput) {
+		in.Range = aws.String(fmt.Sprintf("bytes=%d-", start))
+	}
+}
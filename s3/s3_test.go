@@ -141,6 +141,533 @@ func TestClient_GetObjectMetadata_ok(t *testing.T) {
 	assert.Equal(t, meta, out)
 }
 
+func TestClient_CopyObject_ok(t *testing.T) {
+	// given
+	meta := map[string]*string{"Filename": aws.String("HappyFace.jpg")}
+	_ = cli.PutObject(bucketID, "copy_src_key", bytes.NewReader([]byte("abc")), Metadata(meta))
+
+	// when
+	copyErr := cli.CopyObject(bucketID, "copy_src_key", bucketID, "copy/dst key")
+
+	// then
+	out, getErr := cli.GetObject(bucketID, "copy/dst key")
+	copiedItem, readErr := ioutil.ReadAll(out)
+	copiedMeta, _ := cli.GetObjectMetadata(bucketID, "copy/dst key")
+
+	assert.NoError(t, copyErr)
+	assert.NoError(t, getErr)
+	assert.NoError(t, readErr)
+	assert.Equal(t, "abc", string(copiedItem))
+	assert.Equal(t, meta, copiedMeta)
+}
+
+func TestClient_CopyObject_replaceMetadata(t *testing.T) {
+	// given
+	_ = cli.PutObject(bucketID, "copy_src_key_meta", bytes.NewReader([]byte("abc")),
+		Metadata(map[string]*string{"Filename": aws.String("HappyFace.jpg")}),
+		func(in *s3.PutObjectInput) { in.ContentType = aws.String("image/jpeg") })
+	meta := map[string]*string{"Filename": aws.String("SadFace.png")}
+
+	// when
+	copyErr := cli.CopyObject(bucketID, "copy_src_key_meta", bucketID, "copy_dst_key_meta", ReplaceMetadata(meta))
+
+	// then
+	copiedMeta, _ := cli.GetObjectMetadata(bucketID, "copy_dst_key_meta")
+	head, _ := cli.HeadObject(bucketID, "copy_dst_key_meta")
+	assert.NoError(t, copyErr)
+	assert.Equal(t, "image/jpeg", aws.StringValue(head.ContentType))
+	assert.Equal(t, meta, copiedMeta)
+}
+
+func TestClient_CopyObject_keyNotFound(t *testing.T) {
+	// when
+	copyErr := cli.CopyObject(bucketID, "non_existing_key", bucketID, "copy_dst_key")
+
+	// then
+	isResourceNotFound := func(err error) bool {
+		type resourceNotFound interface {
+			ResourceNotFound() bool
+		}
+		e, ok := err.(resourceNotFound)
+		return ok && e.ResourceNotFound()
+	}
+
+	assert.True(t, isResourceNotFound(copyErr))
+}
+
+func TestClient_MoveObject_ok(t *testing.T) {
+	// given
+	_ = cli.PutObject(bucketID, "move_src_key", bytes.NewReader([]byte("abc")))
+
+	// when
+	moveErr := cli.MoveObject(bucketID, "move_src_key", bucketID, "move_dst_key")
+
+	// then
+	out, getErr := cli.GetObject(bucketID, "move_dst_key")
+	movedItem, _ := ioutil.ReadAll(out)
+	_, srcErr := cli.GetObject(bucketID, "move_src_key")
+
+	assert.NoError(t, moveErr)
+	assert.NoError(t, getErr)
+	assert.Equal(t, "abc", string(movedItem))
+	assert.Error(t, srcErr)
+}
+
+func TestClient_HeadObject_ok(t *testing.T) {
+	// given
+	meta := map[string]*string{"Filename": aws.String("HappyFace.jpg")}
+	_ = cli.PutObject(bucketID, "head_key", bytes.NewReader([]byte("abc")), Metadata(meta), func(in *s3.PutObjectInput) {
+		in.ContentType = aws.String("text/plain")
+	})
+
+	// when
+	out, headErr := cli.HeadObject(bucketID, "head_key")
+
+	// then
+	assert.NoError(t, headErr)
+	assert.Equal(t, int64(3), *out.ContentLength)
+	assert.Equal(t, "text/plain", *out.ContentType)
+	assert.NotEmpty(t, *out.ETag)
+	assert.NotNil(t, out.LastModified)
+	assert.Equal(t, meta, out.Metadata)
+}
+
+func TestClient_HeadObject_keyNotFound(t *testing.T) {
+	// when
+	_, headErr := cli.HeadObject(bucketID, "non_existing_key")
+
+	// then
+	isResourceNotFound := func(err error) bool {
+		type resourceNotFound interface {
+			ResourceNotFound() bool
+		}
+		e, ok := err.(resourceNotFound)
+		return ok && e.ResourceNotFound()
+	}
+
+	assert.True(t, isResourceNotFound(headErr))
+}
+
+func TestClient_GetObject_notModified(t *testing.T) {
+	// given
+	_ = cli.PutObject(bucketID, "conditional_key", bytes.NewReader([]byte("abc")))
+	head, _ := cli.HeadObject(bucketID, "conditional_key")
+
+	// when
+	_, getErr := cli.GetObject(bucketID, "conditional_key", IfNoneMatch(*head.ETag))
+
+	// then
+	isNotModified := func(err error) bool {
+		type notModified interface {
+			NotModified() bool
+		}
+		e, ok := err.(notModified)
+		return ok && e.NotModified()
+	}
+
+	assert.True(t, isNotModified(getErr))
+}
+
+func TestClient_GetObject_preconditionFailed(t *testing.T) {
+	// given
+	_ = cli.PutObject(bucketID, "conditional_key", bytes.NewReader([]byte("abc")))
+
+	// when
+	_, getErr := cli.GetObject(bucketID, "conditional_key", IfMatch("\"different-etag\""))
+
+	// then
+	isPreconditionFailed := func(err error) bool {
+		type preconditionFailed interface {
+			PreconditionFailed() bool
+		}
+		e, ok := err.(preconditionFailed)
+		return ok && e.PreconditionFailed()
+	}
+
+	assert.True(t, isPreconditionFailed(getErr))
+}
+
+func TestClient_GetObject_range(t *testing.T) {
+	// given
+	_ = cli.PutObject(bucketID, "range_key", bytes.NewReader([]byte("abcdef")))
+
+	// when
+	out, getErr := cli.GetObject(bucketID, "range_key", Range(1, 3))
+	part, readErr := ioutil.ReadAll(out)
+
+	// then
+	assert.NoError(t, getErr)
+	assert.NoError(t, readErr)
+	assert.Equal(t, "bcd", string(part))
+}
+
+func TestClient_PutObject_withChecksum_ok(t *testing.T) {
+	// when
+	putErr := cli.PutObject(bucketID, "checksum_key", bytes.NewReader([]byte("abc")), Checksum())
+
+	// then
+	out, getErr := cli.GetObject(bucketID, "checksum_key")
+	data, readErr := ioutil.ReadAll(out)
+	meta, _ := cli.GetObjectMetadata(bucketID, "checksum_key")
+	assert.NoError(t, putErr)
+	assert.NoError(t, getErr)
+	assert.NoError(t, readErr)
+	assert.Equal(t, "abc", string(data))
+	assert.Equal(t, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", *meta[metaSHA256])
+}
+
+func TestClient_UploadObject_ok(t *testing.T) {
+	// given
+	data := bytes.Repeat([]byte("0123456789"), 1200*1024)
+
+	// when
+	uploadErr := cli.UploadObject(bucketID, "upload_key", bytes.NewReader(data))
+
+	// then
+	out, getErr := cli.GetObject(bucketID, "upload_key")
+	saved, readErr := ioutil.ReadAll(out)
+	assert.NoError(t, uploadErr)
+	assert.NoError(t, getErr)
+	assert.NoError(t, readErr)
+	assert.Equal(t, data, saved)
+}
+
+func TestClient_Sync_ok(t *testing.T) {
+	// given
+	dir, _ := ioutil.TempDir("", "goaws")
+	defer os.RemoveAll(dir)
+	_ = ioutil.WriteFile(dir+"/index.html", []byte("<html></html>"), 0644)
+
+	// when
+	actions, syncErr := cli.Sync(dir, bucketID, "sync")
+	resynced, resyncErr := cli.Sync(dir, bucketID, "sync")
+
+	// then
+	head, headErr := cli.HeadObject(bucketID, "sync/index.html")
+	assert.NoError(t, syncErr)
+	assert.NoError(t, resyncErr)
+	assert.NoError(t, headErr)
+	assert.Len(t, actions, 1)
+	assert.Empty(t, resynced)
+	assert.Equal(t, "text/html; charset=utf-8", *head.ContentType)
+}
+
+func TestClient_PutJSON_ok(t *testing.T) {
+	// given
+	doc := map[string]string{"name": "goaws"}
+
+	// when
+	putErr := cli.PutJSON(bucketID, "json_key", doc, Gzip())
+
+	// then
+	var saved map[string]string
+	getErr := cli.GetJSON(bucketID, "json_key", &saved)
+	assert.NoError(t, putErr)
+	assert.NoError(t, getErr)
+	assert.Equal(t, doc, saved)
+}
+
+func TestClient_SelectObjectContent_ok(t *testing.T) {
+	// given
+	_ = cli.PutObject(bucketID, "select.csv", bytes.NewReader([]byte("name,count\na,1\nb,2\n")))
+
+	// when
+	it, selectErr := cli.SelectObjectContent(bucketID, "select.csv", "SELECT s.name FROM S3Object s WHERE s.count = '2'")
+	var records []string
+	for selectErr == nil && it.Next() {
+		records = append(records, string(it.Record()))
+	}
+
+	// then
+	assert.NoError(t, selectErr)
+	assert.NoError(t, it.Err())
+	assert.NoError(t, it.Close())
+	assert.Equal(t, []string{`{"name":"b"}`}, records)
+}
+
+func TestClient_RestoreObject_ok(t *testing.T) {
+	// given
+	_ = cli.PutObject(bucketID, "archived_key", bytes.NewReader([]byte("abc")), StorageClass(s3.StorageClassGlacier))
+	_, getErr := cli.GetObject(bucketID, "archived_key")
+
+	// when
+	restoreErr := cli.RestoreObject(bucketID, "archived_key", 1, RestoreTier(s3.TierBulk))
+	repeatedErr := cli.RestoreObject(bucketID, "archived_key", 1, RestoreTier(s3.TierBulk))
+
+	// then
+	status, statusErr := cli.GetRestoreStatus(bucketID, "archived_key")
+	assert.True(t, getErr.(Error).ObjectArchived())
+	assert.NoError(t, restoreErr)
+	assert.NoError(t, repeatedErr)
+	assert.NoError(t, statusErr)
+	assert.True(t, status.Requested)
+	assert.True(t, status.InProgress)
+	assert.False(t, status.Available())
+}
+
+func TestClient_PutObject_withTagging_ok(t *testing.T) {
+	// given
+	tags := map[string]string{"project": "goaws", "retention": "30 days"}
+
+	// when
+	putErr := cli.PutObject(bucketID, "tagged_key", bytes.NewReader([]byte("abc")), Tagging(tags))
+
+	// then
+	out, getErr := cli.GetObjectTagging(bucketID, "tagged_key")
+	assert.NoError(t, putErr)
+	assert.NoError(t, getErr)
+	assert.Equal(t, tags, out)
+}
+
+func TestClient_PutObjectTagging_ok(t *testing.T) {
+	// given
+	_ = cli.PutObject(bucketID, "tagged_key_123", bytes.NewReader([]byte("abc")))
+	tags := map[string]string{"project": "goaws"}
+
+	// when
+	putErr := cli.PutObjectTagging(bucketID, "tagged_key_123", tags)
+
+	// then
+	out, getErr := cli.GetObjectTagging(bucketID, "tagged_key_123")
+	assert.NoError(t, putErr)
+	assert.NoError(t, getErr)
+	assert.Equal(t, tags, out)
+}
+
+func TestClient_DeleteObjectTagging_ok(t *testing.T) {
+	// given
+	_ = cli.PutObject(bucketID, "tagged_key_456", bytes.NewReader([]byte("abc")), Tagging(map[string]string{"project": "goaws"}))
+
+	// when
+	deleteErr := cli.DeleteObjectTagging(bucketID, "tagged_key_456")
+
+	// then
+	out, getErr := cli.GetObjectTagging(bucketID, "tagged_key_456")
+	assert.NoError(t, deleteErr)
+	assert.NoError(t, getErr)
+	assert.Empty(t, out)
+}
+
+func TestClient_PutObjectACL_ok(t *testing.T) {
+	// given
+	_ = cli.PutObject(bucketID, "acl_key", bytes.NewReader([]byte("abc")), ACL(s3.ObjectCannedACLPrivate))
+
+	// when
+	aclErr := cli.PutObjectACL(bucketID, "acl_key", s3.ObjectCannedACLBucketOwnerFullControl)
+
+	// then
+	assert.NoError(t, aclErr)
+}
+
+func TestClient_TransitionStorageClass_ok(t *testing.T) {
+	// given
+	_ = cli.PutObject(bucketID, "storage_class_key", bytes.NewReader([]byte("abc")))
+
+	// when
+	transitionErr := cli.TransitionStorageClass(bucketID, "storage_class_key", s3.StorageClassStandardIa)
+
+	// then
+	out, headErr := cli.HeadObject(bucketID, "storage_class_key")
+	assert.NoError(t, transitionErr)
+	assert.NoError(t, headErr)
+	assert.Equal(t, s3.StorageClassStandardIa, *out.StorageClass)
+}
+
+func TestClient_PutObject_withSSECustomerKey_ok(t *testing.T) {
+	// given
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	// when
+	putErr := cli.PutObject(bucketID, "ssec_key", bytes.NewReader([]byte("abc")), SSECustomerKey(key))
+
+	// then
+	out, getErr := cli.GetObject(bucketID, "ssec_key", GetSSECustomerKey(key))
+	savedItem, readErr := ioutil.ReadAll(out)
+	_, headErr := cli.HeadObject(bucketID, "ssec_key", HeadSSECustomerKey(key))
+
+	assert.NoError(t, putErr)
+	assert.NoError(t, getErr)
+	assert.NoError(t, readErr)
+	assert.NoError(t, headErr)
+	assert.Equal(t, "abc", string(savedItem))
+}
+
+func TestClient_PutObject_withSSES3_ok(t *testing.T) {
+	// when
+	putErr := cli.PutObject(bucketID, "sse_s3_key", bytes.NewReader([]byte("abc")), SSES3())
+
+	// then
+	assert.NoError(t, putErr)
+}
+
+func TestEncryptionClient_PutObject_ok(t *testing.T) {
+	// given
+	provider, _ := NewStaticKeyProvider([]byte("0123456789abcdef0123456789abcdef"))
+	encCli := NewEncryptionClient(cli, provider)
+
+	// when
+	putErr := encCli.PutObject(bucketID, "client_side_encrypted_key", bytes.NewReader([]byte("abc")))
+
+	// then
+	raw, _ := cli.GetObject(bucketID, "client_side_encrypted_key")
+	rawItem, _ := ioutil.ReadAll(raw)
+	out, getErr := encCli.GetObject(bucketID, "client_side_encrypted_key")
+	savedItem, readErr := ioutil.ReadAll(out)
+
+	assert.NoError(t, putErr)
+	assert.NoError(t, getErr)
+	assert.NoError(t, readErr)
+	assert.NotEqual(t, "abc", string(rawItem))
+	assert.Equal(t, "abc", string(savedItem))
+}
+
+func TestEncryptionClient_GetObject_decryptionFailed(t *testing.T) {
+	// given
+	provider, _ := NewStaticKeyProvider([]byte("0123456789abcdef0123456789abcdef"))
+	encCli := NewEncryptionClient(cli, provider)
+	_ = cli.PutObject(bucketID, "not_encrypted_key", bytes.NewReader([]byte("abc")))
+
+	// when
+	_, getErr := encCli.GetObject(bucketID, "not_encrypted_key")
+
+	// then
+	isDecryptionFailed := func(err error) bool {
+		type decryptionFailed interface {
+			DecryptionFailed() bool
+		}
+		e, ok := err.(decryptionFailed)
+		return ok && e.DecryptionFailed()
+	}
+
+	assert.True(t, isDecryptionFailed(getErr))
+}
+
+func TestClient_CreateBucket_ok(t *testing.T) {
+	// given
+	bucket := xid.New().String()
+
+	// when
+	createErr := cli.CreateBucket(bucket, BucketRegion(endpoints.EuWest1RegionID))
+	_ = cli.PutObject(bucket, "some_random_key", bytes.NewReader([]byte("abc")))
+
+	// then
+	exists, existsErr := cli.BucketExists(bucket)
+	head, headErr := cli.HeadBucket(bucket)
+	deleteErr := cli.DeleteBucket(bucket, true)
+	existsAfterDelete, _ := cli.BucketExists(bucket)
+
+	assert.NoError(t, createErr)
+	assert.NoError(t, existsErr)
+	assert.NoError(t, headErr)
+	assert.NoError(t, deleteErr)
+	assert.True(t, exists)
+	assert.Equal(t, endpoints.EuWest1RegionID, *head.Region)
+	assert.False(t, existsAfterDelete)
+}
+
+func TestClient_BucketExists_notFound(t *testing.T) {
+	// when
+	exists, err := cli.BucketExists(xid.New().String())
+
+	// then
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestClient_SetBucketVersioning_ok(t *testing.T) {
+	// given
+	bucket := xid.New().String()
+	_ = cli.CreateBucket(bucket, BucketRegion(endpoints.EuWest1RegionID))
+	defer cli.DeleteBucket(bucket, true)
+
+	// when
+	setErr := cli.SetBucketVersioning(bucket, true)
+
+	// then
+	status, getErr := cli.GetBucketVersioning(bucket)
+	assert.NoError(t, setErr)
+	assert.NoError(t, getErr)
+	assert.Equal(t, s3.BucketVersioningStatusEnabled, status)
+}
+
+func TestClient_RestoreVersion_ok(t *testing.T) {
+	// given
+	bucket := xid.New().String()
+	_ = cli.CreateBucket(bucket, BucketRegion(endpoints.EuWest1RegionID))
+	defer cli.DeleteBucket(bucket, true)
+	_ = cli.SetBucketVersioning(bucket, true)
+	_ = cli.PutObject(bucket, "versioned_key", bytes.NewReader([]byte("v1")))
+	_ = cli.PutObject(bucket, "versioned_key", bytes.NewReader([]byte("v2")))
+	_ = cli.DeleteObject(bucket, "versioned_key")
+
+	var versions []*ObjectVersion
+	byData := make(map[string]string)
+	it := cli.ListObjectVersions(bucket, "versioned_key")
+	for it.Next() {
+		v := it.Version()
+		versions = append(versions, v)
+		if !*v.DeleteMarker {
+			body, _ := cli.GetObject(bucket, "versioned_key", GetVersionID(*v.VersionID))
+			data, _ := ioutil.ReadAll(body)
+			byData[string(data)] = *v.VersionID
+		}
+	}
+
+	// when
+	restoreErr := cli.RestoreVersion(bucket, "versioned_key", byData["v1"])
+
+	// then
+	out, getErr := cli.GetObject(bucket, "versioned_key")
+	data, _ := ioutil.ReadAll(out)
+	assert.NoError(t, it.Err())
+	assert.NoError(t, restoreErr)
+	assert.NoError(t, getErr)
+	assert.Len(t, versions, 3)
+	assert.True(t, *versions[0].DeleteMarker)
+	assert.True(t, *versions[0].IsLatest)
+	assert.Len(t, byData, 2)
+	assert.Equal(t, "v1", string(data))
+}
+
+func TestClient_PutBucketLifecycle_ok(t *testing.T) {
+	// given
+	rules := []LifecycleRule{{ID: "expire-tmp", Prefix: "tmp/", Enabled: true, ExpirationDays: 1}}
+
+	// when
+	putErr := cli.PutBucketLifecycle(bucketID, rules)
+
+	// then
+	out, getErr := cli.GetBucketLifecycle(bucketID)
+	deleteErr := cli.DeleteBucketLifecycle(bucketID)
+	assert.NoError(t, putErr)
+	assert.NoError(t, getErr)
+	assert.NoError(t, deleteErr)
+	assert.Equal(t, rules, out)
+}
+
+func TestClient_PutBucketCORS_ok(t *testing.T) {
+	// given
+	rules := []CORSRule{{
+		AllowedMethods: []string{"GET", "PUT"},
+		AllowedOrigins: []string{"https://example.com"},
+		AllowedHeaders: []string{"*"},
+		MaxAgeSeconds:  300,
+	}}
+
+	// when
+	putErr := cli.PutBucketCORS(bucketID, rules)
+
+	// then
+	out, getErr := cli.GetBucketCORS(bucketID)
+	deleteErr := cli.DeleteBucketCORS(bucketID)
+	empty, _ := cli.GetBucketCORS(bucketID)
+	assert.NoError(t, putErr)
+	assert.NoError(t, getErr)
+	assert.NoError(t, deleteErr)
+	assert.Equal(t, rules, out)
+	assert.Empty(t, empty)
+}
+
 func TestMain(m *testing.M) {
 	setupS3()
 	code := m.Run()
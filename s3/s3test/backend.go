@@ -0,0 +1,327 @@
+package s3test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const metaDir = ".meta"
+
+var (
+	ErrNoSuchBucket   = errors.New("no such bucket")
+	ErrNoSuchKey      = errors.New("no such key")
+	ErrBucketExists   = errors.New("bucket already exists")
+	ErrBucketNotEmpty = errors.New("bucket not empty")
+
+	ErrInvalidBucketName = errors.New("invalid bucket name")
+)
+
+// Backend stores buckets and objects served by Handler. Implementations return ErrNoSuchBucket, ErrNoSuchKey,
+// ErrBucketExists, ErrBucketNotEmpty and ErrInvalidBucketName so Handler can translate them into S3 error codes.
+type Backend interface {
+	CreateBucket(bucket string) error
+	DeleteBucket(bucket string) error
+	HasBucket(bucket string) bool
+	PutObject(bucket, key string, obj *Object) error
+	GetObject(bucket, key string) (*Object, error)
+	DeleteObject(bucket, key string) error
+	ListKeys(bucket, prefix string) ([]string, error)
+}
+
+type memoryBackend struct {
+	mu      sync.RWMutex
+	buckets map[string]map[string]*Object
+}
+
+func NewMemoryBackend(buckets ...string) Backend {
+	b := &memoryBackend{buckets: make(map[string]map[string]*Object)}
+	for _, bucket := range buckets {
+		b.buckets[bucket] = make(map[string]*Object)
+	}
+	return b
+}
+
+func (b *memoryBackend) CreateBucket(bucket string) error {
+	if !validBucketName(bucket) {
+		return ErrInvalidBucketName
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.buckets[bucket]; ok {
+		return ErrBucketExists
+	}
+	b.buckets[bucket] = make(map[string]*Object)
+	return nil
+}
+
+func (b *memoryBackend) DeleteBucket(bucket string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	objects, ok := b.buckets[bucket]
+	if !ok {
+		return ErrNoSuchBucket
+	}
+	if len(objects) > 0 {
+		return ErrBucketNotEmpty
+	}
+	delete(b.buckets, bucket)
+	return nil
+}
+
+func (b *memoryBackend) HasBucket(bucket string) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	_, ok := b.buckets[bucket]
+	return ok
+}
+
+func (b *memoryBackend) PutObject(bucket, key string, obj *Object) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	objects, ok := b.buckets[bucket]
+	if !ok {
+		return ErrNoSuchBucket
+	}
+	objects[key] = obj.clone()
+	return nil
+}
+
+func (b *memoryBackend) GetObject(bucket, key string) (*Object, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	objects, ok := b.buckets[bucket]
+	if !ok {
+		return nil, ErrNoSuchBucket
+	}
+	obj, ok := objects[key]
+	if !ok {
+		return nil, ErrNoSuchKey
+	}
+	return obj.clone(), nil
+}
+
+func (b *memoryBackend) DeleteObject(bucket, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	objects, ok := b.buckets[bucket]
+	if !ok {
+		return ErrNoSuchBucket
+	}
+	delete(objects, key)
+	return nil
+}
+
+func (b *memoryBackend) ListKeys(bucket, prefix string) ([]string, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	objects, ok := b.buckets[bucket]
+	if !ok {
+		return nil, ErrNoSuchBucket
+	}
+
+	var keys []string
+	for k := range objects {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// dirBackend keeps every bucket as a sub-directory of root. Object data is stored in a file named after the escaped
+// key and everything else in a JSON file of the same name inside the bucket's .meta directory.
+type dirBackend struct {
+	mu   sync.RWMutex
+	root string
+}
+
+func NewDirBackend(root string) (Backend, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, errors.Wrap(err, "unable to create backend directory")
+	}
+	return &dirBackend{root: root}, nil
+}
+
+func (b *dirBackend) CreateBucket(bucket string) error {
+	if !validBucketName(bucket) {
+		return ErrInvalidBucketName
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.hasBucket(bucket) {
+		return ErrBucketExists
+	}
+	return os.MkdirAll(filepath.Join(b.root, bucket, metaDir), 0755)
+}
+
+func (b *dirBackend) DeleteBucket(bucket string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	keys, err := b.listKeys(bucket, "")
+	if err != nil {
+		return err
+	}
+	if len(keys) > 0 {
+		return ErrBucketNotEmpty
+	}
+	return os.RemoveAll(filepath.Join(b.root, bucket))
+}
+
+func (b *dirBackend) HasBucket(bucket string) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.hasBucket(bucket)
+}
+
+func (b *dirBackend) PutObject(bucket, key string, obj *Object) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.hasBucket(bucket) {
+		return ErrNoSuchBucket
+	}
+
+	meta := *obj
+	meta.Data = nil
+	encoded, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+
+	dataPath, metaPath := b.paths(bucket, key)
+	if err := ioutil.WriteFile(dataPath, obj.Data, 0644); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(metaPath, encoded, 0644)
+}
+
+func (b *dirBackend) GetObject(bucket, key string) (*Object, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if !b.hasBucket(bucket) {
+		return nil, ErrNoSuchBucket
+	}
+
+	dataPath, metaPath := b.paths(bucket, key)
+	encoded, err := ioutil.ReadFile(metaPath)
+	if os.IsNotExist(err) {
+		return nil, ErrNoSuchKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	obj := &Object{}
+	if err := json.Unmarshal(encoded, obj); err != nil {
+		return nil, err
+	}
+	if obj.Data, err = ioutil.ReadFile(dataPath); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (b *dirBackend) DeleteObject(bucket, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.hasBucket(bucket) {
+		return ErrNoSuchBucket
+	}
+
+	dataPath, metaPath := b.paths(bucket, key)
+	for _, p := range []string{metaPath, dataPath} {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *dirBackend) ListKeys(bucket, prefix string) ([]string, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.listKeys(bucket, prefix)
+}
+
+func (b *dirBackend) listKeys(bucket, prefix string) ([]string, error) {
+	if !b.hasBucket(bucket) {
+		return nil, ErrNoSuchBucket
+	}
+
+	files, err := ioutil.ReadDir(filepath.Join(b.root, bucket, metaDir))
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, f := range files {
+		key, err := url.PathUnescape(f.Name())
+		if err != nil {
+			continue
+		}
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (b *dirBackend) hasBucket(bucket string) bool {
+	if !validBucketName(bucket) {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(b.root, bucket, metaDir))
+	return err == nil && info.IsDir()
+}
+
+func (b *dirBackend) paths(bucket, key string) (dataPath, metaPath string) {
+	name := escapeKey(key)
+	return filepath.Join(b.root, bucket, name), filepath.Join(b.root, bucket, metaDir, name)
+}
+
+// escapeKey turns object key into a single file name, leading dot is escaped so keys never clash with .meta directory.
+func escapeKey(key string) string {
+	name := url.PathEscape(key)
+	if strings.HasPrefix(name, ".") {
+		name = "%2E" + name[1:]
+	}
+	return name
+}
+
+// validBucketName is a relaxed version of S3 naming rules, good enough to keep bucket names usable as directory names.
+func validBucketName(bucket string) bool {
+	if len(bucket) < 3 || len(bucket) > 63 || strings.HasPrefix(bucket, ".") {
+		return false
+	}
+	for _, c := range bucket {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' && c != '.' && c != '_' {
+			return false
+		}
+	}
+	return true
+}
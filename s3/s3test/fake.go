@@ -0,0 +1,499 @@
+package s3test
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Ryanair/goaws/internal"
+	goawss3 "github.com/Ryanair/goaws/s3"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+const (
+	defaultContentType  = "binary/octet-stream"
+	errCodeInvalidRange = "InvalidRange"
+	// nullVersionID is the version of objects in buckets without versioning, the only version Fake keeps
+	nullVersionID        = "null"
+	errCodeNoSuchVersion = "NoSuchVersion"
+)
+
+// Object is a single object kept by Fake or Backend.
+type Object struct {
+	Data            []byte
+	ETag            string
+	ContentType     string
+	ContentEncoding string
+	CacheControl    string
+	LastModified    time.Time
+	StorageClass    string
+	Metadata        map[string]string
+	Tags            map[string]string
+}
+
+// Fake is an in-memory implementation of s3.ObjectAPI, it returns the same error codes as S3 so s3.Error predicates
+// behave the same way as with the real Client. It is safe for concurrent use.
+type Fake struct {
+	mu      sync.RWMutex
+	buckets map[string]map[string]*Object
+	now     func() time.Time
+}
+
+func NewFake(buckets ...string) *Fake {
+	f := &Fake{
+		buckets: make(map[string]map[string]*Object),
+		now:     time.Now,
+	}
+	for _, b := range buckets {
+		f.CreateBucket(b)
+	}
+	return f
+}
+
+func (f *Fake) CreateBucket(bucket string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.buckets[bucket]; !ok {
+		f.buckets[bucket] = make(map[string]*Object)
+	}
+}
+
+// Object returns a copy of stored object, it is meant for assertions in tests.
+func (f *Fake) Object(bucket, key string) (Object, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	obj, ok := f.buckets[bucket][key]
+	if !ok {
+		return Object{}, false
+	}
+	return *obj.clone(), true
+}
+
+func (f *Fake) PutObject(bucket, key string, body io.ReadSeeker, options ...func(*s3.PutObjectInput)) error {
+	in := &s3.PutObjectInput{
+		Body:   body,
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	for _, opt := range options {
+		opt(in)
+	}
+	if err := goawss3.PreparePutObject(in); err != nil {
+		return err
+	}
+
+	var data []byte
+	if in.Body != nil {
+		var err error
+		if data, err = ioutil.ReadAll(in.Body); err != nil {
+			return wrapErr(err, "put object with metadata failed")
+		}
+	}
+
+	if in.ContentMD5 != nil {
+		sum := md5.Sum(data)
+		if base64.StdEncoding.EncodeToString(sum[:]) != *in.ContentMD5 {
+			return wrapErr(awserr.New(goawss3.ErrCodeBadDigest, "The Content-MD5 you specified did not match what we received.", nil),
+				"put object with metadata failed")
+		}
+	}
+
+	tags, err := parseTags(in.Tagging)
+	if err != nil {
+		return wrapErr(awserr.New("InvalidTag", err.Error(), err), "put object with metadata failed")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	objects, ok := f.buckets[bucket]
+	if !ok {
+		return noSuchBucket("put object with metadata failed")
+	}
+
+	objects[key] = &Object{
+		Data:            data,
+		ETag:            etag(data),
+		ContentType:     stringOr(in.ContentType, defaultContentType),
+		ContentEncoding: aws.StringValue(in.ContentEncoding),
+		CacheControl:    aws.StringValue(in.CacheControl),
+		LastModified:    f.now(),
+		StorageClass:    stringOr(in.StorageClass, s3.StorageClassStandard),
+		Metadata:        canonicalMetadata(in.Metadata),
+		Tags:            tags,
+	}
+	return nil
+}
+
+func (f *Fake) GetObject(bucket, key string, options ...func(*s3.GetObjectInput)) (io.ReadCloser, error) {
+	in := &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	for _, opt := range options {
+		opt(in)
+	}
+
+	const msg = "get object failed"
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	obj, err := f.object(bucket, key, msg, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(in.VersionId, msg); err != nil {
+		return nil, err
+	}
+
+	if code := checkConditions(obj, in); code != "" {
+		return nil, wrapErr(awserr.New(code, http.StatusText(statusFor(code)), nil), msg)
+	}
+
+	data := obj.Data
+	if in.Range != nil {
+		start, end, ok := parseRange(aws.StringValue(in.Range), int64(len(data)))
+		if !ok {
+			return nil, wrapErr(awserr.New(errCodeInvalidRange, "The requested range is not satisfiable", nil), msg)
+		}
+		data = data[start : end+1]
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(append([]byte(nil), data...))), nil
+}
+
+func (f *Fake) HeadObject(bucket, key string, options ...func(*s3.HeadObjectInput)) (*goawss3.HeadObjectResult, error) {
+	in := &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	for _, opt := range options {
+		opt(in)
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	obj, err := f.object(bucket, key, "head object failed", true)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(in.VersionId, "head object failed"); err != nil {
+		return nil, err
+	}
+
+	return headResult(obj), nil
+}
+
+func (f *Fake) GetObjectMetadata(bucket, key string) (map[string]*string, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	obj, err := f.object(bucket, key, "get object metadata failed", true)
+	if err != nil {
+		return nil, err
+	}
+
+	return headResult(obj).Metadata, nil
+}
+
+// DeleteObject removes the object. Fake keeps no versions, like a bucket without versioning, so only the "null" version
+// can be deleted and any other version is reported as missing, as it is by GetObject and HeadObject.
+func (f *Fake) DeleteObject(bucket, key string, options ...func(*s3.DeleteObjectInput)) error {
+	in := &s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	for _, opt := range options {
+		opt(in)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	objects, ok := f.buckets[bucket]
+	if !ok {
+		return noSuchBucket("delete object failed")
+	}
+	if err := checkVersion(in.VersionId, "delete object failed"); err != nil {
+		return err
+	}
+
+	delete(objects, key)
+	return nil
+}
+
+func (f *Fake) ListObjects(bucket, prefix string) ([]*goawss3.ObjectSummary, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	objects, ok := f.buckets[bucket]
+	if !ok {
+		return nil, noSuchBucket("list objects failed")
+	}
+
+	keys := make([]string, 0, len(objects))
+	for k := range objects {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var summaries []*goawss3.ObjectSummary
+	for _, k := range keys {
+		obj := objects[k]
+		summaries = append(summaries, &goawss3.ObjectSummary{
+			Key:          aws.String(k),
+			ETag:         aws.String(obj.ETag),
+			Size:         aws.Int64(int64(len(obj.Data))),
+			LastModified: aws.Time(obj.LastModified),
+			StorageClass: aws.String(obj.StorageClass),
+		})
+	}
+	return summaries, nil
+}
+
+func (f *Fake) CopyObject(srcBucket, srcKey, dstBucket, dstKey string, options ...func(*s3.CopyObjectInput)) error {
+	in := &s3.CopyObjectInput{
+		Bucket: aws.String(dstBucket),
+		Key:    aws.String(dstKey),
+	}
+	for _, opt := range options {
+		opt(in)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	src, err := f.object(srcBucket, srcKey, "copy object head source failed", true)
+	if err != nil {
+		return err
+	}
+
+	objects, ok := f.buckets[dstBucket]
+	if !ok {
+		return noSuchBucket("copy object failed")
+	}
+
+	dst := src.clone()
+	dst.LastModified = f.now()
+	if in.StorageClass != nil {
+		dst.StorageClass = aws.StringValue(in.StorageClass)
+	}
+	if aws.StringValue(in.MetadataDirective) == s3.MetadataDirectiveReplace {
+		dst.Metadata = canonicalMetadata(in.Metadata)
+		dst.ContentType = stringOr(in.ContentType, src.ContentType)
+		dst.ContentEncoding = aws.StringValue(in.ContentEncoding)
+		dst.CacheControl = aws.StringValue(in.CacheControl)
+	}
+	if aws.StringValue(in.TaggingDirective) == s3.TaggingDirectiveReplace {
+		tags, err := parseTags(in.Tagging)
+		if err != nil {
+			return wrapErr(awserr.New("InvalidTag", err.Error(), err), "copy object failed")
+		}
+		dst.Tags = tags
+	}
+
+	objects[dstKey] = dst
+	return nil
+}
+
+// object looks up stored object, HEAD requests get NotFound for missing bucket or key as no error body is sent by S3.
+func (f *Fake) object(bucket, key, msg string, head bool) (*Object, error) {
+	if obj, ok := f.buckets[bucket][key]; ok {
+		return obj, nil
+	}
+
+	switch {
+	case head:
+		return nil, wrapErr(awserr.New(goawss3.ErrCodeNotFound, "Not Found", nil), msg)
+	case f.buckets[bucket] == nil:
+		return nil, noSuchBucket(msg)
+	default:
+		return nil, wrapErr(awserr.New(s3.ErrCodeNoSuchKey, "The specified key does not exist.", nil), msg)
+	}
+}
+
+// checkVersion fails for any version other than "null", the only one Fake keeps.
+func checkVersion(versionID *string, msg string) error {
+	if versionID != nil && *versionID != nullVersionID {
+		return wrapErr(awserr.New(errCodeNoSuchVersion, "The specified version does not exist.", nil), msg)
+	}
+	return nil
+}
+
+func (o *Object) clone() *Object {
+	c := *o
+	c.Data = append([]byte(nil), o.Data...)
+	c.Metadata = make(map[string]string, len(o.Metadata))
+	for k, v := range o.Metadata {
+		c.Metadata[k] = v
+	}
+	c.Tags = make(map[string]string, len(o.Tags))
+	for k, v := range o.Tags {
+		c.Tags[k] = v
+	}
+	return &c
+}
+
+func headResult(obj *Object) *goawss3.HeadObjectResult {
+	meta := make(map[string]*string, len(obj.Metadata))
+	for k, v := range obj.Metadata {
+		meta[k] = aws.String(v)
+	}
+
+	result := &goawss3.HeadObjectResult{
+		ETag:          aws.String(obj.ETag),
+		ContentLength: aws.Int64(int64(len(obj.Data))),
+		ContentType:   aws.String(obj.ContentType),
+		LastModified:  aws.Time(obj.LastModified),
+		Metadata:      meta,
+	}
+	if obj.ContentEncoding != "" {
+		result.ContentEncoding = aws.String(obj.ContentEncoding)
+	}
+	if obj.CacheControl != "" {
+		result.CacheControl = aws.String(obj.CacheControl)
+	}
+	// S3 omits the storage class header for STANDARD objects
+	if obj.StorageClass != s3.StorageClassStandard {
+		result.StorageClass = aws.String(obj.StorageClass)
+	}
+	return result
+}
+
+func checkConditions(obj *Object, in *s3.GetObjectInput) string {
+	switch {
+	case in.IfMatch != nil && !etagMatches(aws.StringValue(in.IfMatch), obj.ETag):
+		return goawss3.ErrCodePreconditionFailed
+	case in.IfUnmodifiedSince != nil && obj.LastModified.After(aws.TimeValue(in.IfUnmodifiedSince)):
+		return goawss3.ErrCodePreconditionFailed
+	case in.IfNoneMatch != nil && etagMatches(aws.StringValue(in.IfNoneMatch), obj.ETag):
+		return goawss3.ErrCodeNotModified
+	case in.IfNoneMatch == nil && in.IfModifiedSince != nil && !obj.LastModified.After(aws.TimeValue(in.IfModifiedSince)):
+		return goawss3.ErrCodeNotModified
+	}
+	return ""
+}
+
+func statusFor(code string) int {
+	if code == goawss3.ErrCodeNotModified {
+		return http.StatusNotModified
+	}
+	return http.StatusPreconditionFailed
+}
+
+func etagMatches(condition, etag string) bool {
+	for _, c := range strings.Split(condition, ",") {
+		c = strings.TrimSpace(c)
+		if c == "*" || strings.Trim(c, `"`) == strings.Trim(etag, `"`) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRange parses single byte range header value, returning inclusive start and end offsets.
+func parseRange(value string, size int64) (start, end int64, ok bool) {
+	spec := strings.TrimPrefix(value, "bytes=")
+	if spec == value || strings.Contains(spec, ",") {
+		return 0, 0, false
+	}
+
+	parts := strings.SplitN(spec, "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+
+	var err error
+	switch {
+	case parts[0] == "":
+		var suffix int64
+		if suffix, err = strconv.ParseInt(parts[1], 10, 64); err != nil || suffix <= 0 {
+			return 0, 0, false
+		}
+		if suffix > size {
+			suffix = size
+		}
+		start, end = size-suffix, size-1
+	default:
+		if start, err = strconv.ParseInt(parts[0], 10, 64); err != nil {
+			return 0, 0, false
+		}
+		end = size - 1
+		if parts[1] != "" {
+			if end, err = strconv.ParseInt(parts[1], 10, 64); err != nil || end < start {
+				return 0, 0, false
+			}
+			if end >= size {
+				end = size - 1
+			}
+		}
+	}
+
+	if start >= size || start < 0 {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+func parseTags(tagging *string) (map[string]string, error) {
+	tags := make(map[string]string)
+	if tagging == nil {
+		return tags, nil
+	}
+
+	values, err := url.ParseQuery(*tagging)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range values {
+		tags[k] = v[0]
+	}
+	return tags, nil
+}
+
+func canonicalMetadata(meta map[string]*string) map[string]string {
+	canonical := make(map[string]string, len(meta))
+	for k, v := range meta {
+		canonical[http.CanonicalHeaderKey(k)] = aws.StringValue(v)
+	}
+	return canonical
+}
+
+func etag(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func stringOr(s *string, def string) string {
+	if s == nil || *s == "" {
+		return def
+	}
+	return *s
+}
+
+func noSuchBucket(msg string) error {
+	return wrapErr(awserr.New(s3.ErrCodeNoSuchBucket, "The specified bucket does not exist", nil), msg)
+}
+
+func wrapErr(err error, msg string) error {
+	return goawss3.Error(internal.WrapErr(err, msg))
+}
@@ -0,0 +1,200 @@
+package s3test_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/Ryanair/goaws/s3"
+	"github.com/Ryanair/goaws/s3/s3test"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awss3 "github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+const bucket = "fake-bucket"
+
+func TestFakeImplementsObjectAPI(t *testing.T) {
+	var _ s3.ObjectAPI = &s3test.Fake{}
+}
+
+func TestFake_PutObject_ok(t *testing.T) {
+	// given
+	fake := s3test.NewFake(bucket)
+	meta := map[string]*string{"filename": aws.String("HappyFace.jpg")}
+
+	// when
+	putErr := fake.PutObject(bucket, "some_random_key", bytes.NewReader([]byte("abc")), s3.Metadata(meta),
+		s3.Tagging(map[string]string{"project": "goaws"}))
+
+	// then
+	out, getErr := fake.GetObject(bucket, "some_random_key")
+	savedItem, _ := ioutil.ReadAll(out)
+	head, headErr := fake.HeadObject(bucket, "some_random_key")
+	obj, _ := fake.Object(bucket, "some_random_key")
+
+	assert.NoError(t, putErr)
+	assert.NoError(t, getErr)
+	assert.NoError(t, headErr)
+	assert.Equal(t, "abc", string(savedItem))
+	assert.Equal(t, map[string]*string{"Filename": aws.String("HappyFace.jpg")}, head.Metadata)
+	assert.Equal(t, int64(3), *head.ContentLength)
+	assert.Equal(t, `"900150983cd24fb0d6963f7d28e17f72"`, *head.ETag)
+	assert.Equal(t, map[string]string{"project": "goaws"}, obj.Tags)
+}
+
+func TestFake_errors(t *testing.T) {
+	// given
+	fake := s3test.NewFake(bucket)
+
+	type predicates interface {
+		KeyNotFound() bool
+		BucketNotFound() bool
+		ResourceNotFound() bool
+	}
+	asPredicates := func(err error) predicates {
+		e, ok := err.(predicates)
+		assert.True(t, ok)
+		return e
+	}
+
+	// when
+	_, getErr := fake.GetObject(bucket, "non_existing_key")
+	_, getBucketErr := fake.GetObject("non_existing_bucket", "some_random_key")
+	_, headErr := fake.HeadObject(bucket, "non_existing_key")
+	putErr := fake.PutObject("non_existing_bucket", "some_random_key", bytes.NewReader(nil))
+	copyErr := fake.CopyObject(bucket, "non_existing_key", bucket, "dst")
+
+	// then
+	assert.True(t, asPredicates(getErr).KeyNotFound())
+	assert.True(t, asPredicates(getBucketErr).BucketNotFound())
+	assert.True(t, asPredicates(headErr).ResourceNotFound())
+	assert.True(t, asPredicates(putErr).BucketNotFound())
+	assert.True(t, asPredicates(copyErr).ResourceNotFound())
+}
+
+func TestFake_GetObject_conditions(t *testing.T) {
+	// given
+	fake := s3test.NewFake(bucket)
+	_ = fake.PutObject(bucket, "some_random_key", bytes.NewReader([]byte("abcdef")))
+	head, _ := fake.HeadObject(bucket, "some_random_key")
+
+	isNotModified := func(err error) bool {
+		e, ok := err.(interface{ NotModified() bool })
+		return ok && e.NotModified()
+	}
+	isPreconditionFailed := func(err error) bool {
+		e, ok := err.(interface{ PreconditionFailed() bool })
+		return ok && e.PreconditionFailed()
+	}
+
+	// when
+	_, noneMatchErr := fake.GetObject(bucket, "some_random_key", s3.IfNoneMatch(*head.ETag))
+	_, matchErr := fake.GetObject(bucket, "some_random_key", s3.IfMatch(`"other"`))
+	_, modifiedErr := fake.GetObject(bucket, "some_random_key", s3.IfModifiedSince(time.Now().Add(time.Hour)))
+	out, rangeErr := fake.GetObject(bucket, "some_random_key", s3.Range(1, 3))
+	part, _ := ioutil.ReadAll(out)
+
+	// then
+	assert.True(t, isNotModified(noneMatchErr))
+	assert.True(t, isPreconditionFailed(matchErr))
+	assert.True(t, isNotModified(modifiedErr))
+	assert.NoError(t, rangeErr)
+	assert.Equal(t, "bcd", string(part))
+}
+
+func TestFake_ListObjects_ok(t *testing.T) {
+	// given
+	fake := s3test.NewFake(bucket)
+	_ = fake.PutObject(bucket, "docs/b", bytes.NewReader([]byte("b")))
+	_ = fake.PutObject(bucket, "docs/a", bytes.NewReader([]byte("a")))
+	_ = fake.PutObject(bucket, "images/c", bytes.NewReader([]byte("c")))
+
+	// when
+	out, err := fake.ListObjects(bucket, "docs/")
+
+	// then
+	assert.NoError(t, err)
+	assert.Len(t, out, 2)
+	assert.Equal(t, "docs/a", *out[0].Key)
+	assert.Equal(t, "docs/b", *out[1].Key)
+}
+
+func TestFake_CopyObject_ok(t *testing.T) {
+	// given
+	fake := s3test.NewFake(bucket, "other-bucket")
+	_ = fake.PutObject(bucket, "src", bytes.NewReader([]byte("abc")),
+		s3.Metadata(map[string]*string{"Filename": aws.String("HappyFace.jpg")}),
+		func(in *awss3.PutObjectInput) { in.ContentType = aws.String("image/jpeg") })
+	meta := map[string]*string{"Filename": aws.String("SadFace.png")}
+
+	// when
+	copyErr := fake.CopyObject(bucket, "src", "other-bucket", "dst", s3.ReplaceMetadata(meta),
+		s3.CopyStorageClass(awss3.StorageClassStandardIa))
+	deleteErr := fake.DeleteObject(bucket, "src")
+
+	// then
+	head, headErr := fake.HeadObject("other-bucket", "dst")
+	_, srcFound := fake.Object(bucket, "src")
+
+	assert.NoError(t, copyErr)
+	assert.NoError(t, deleteErr)
+	assert.NoError(t, headErr)
+	assert.False(t, srcFound)
+	assert.Equal(t, meta, head.Metadata)
+	assert.Equal(t, awss3.StorageClassStandardIa, *head.StorageClass)
+	assert.Equal(t, "image/jpeg", *head.ContentType)
+}
+
+func TestFake_DeleteObject_version(t *testing.T) {
+	// given
+	fake := s3test.NewFake(bucket)
+	_ = fake.PutObject(bucket, "key", bytes.NewReader([]byte("abc")))
+
+	// when
+	missingVersionErr := fake.DeleteObject(bucket, "key", s3.DeleteVersionID("3HL4kqtJlcpXroDTDmJ"))
+	_, keptFound := fake.Object(bucket, "key")
+	deleteErr := fake.DeleteObject(bucket, "key", s3.DeleteVersionID("null"))
+	_, deletedFound := fake.Object(bucket, "key")
+
+	// then
+	assert.Equal(t, "NoSuchVersion", missingVersionErr.(s3.Error).Code)
+	assert.True(t, keptFound)
+	assert.NoError(t, deleteErr)
+	assert.False(t, deletedFound)
+}
+
+func TestFake_GetObject_version(t *testing.T) {
+	// given
+	fake := s3test.NewFake(bucket)
+	_ = fake.PutObject(bucket, "key", bytes.NewReader([]byte("abc")))
+
+	// when
+	_, getErr := fake.GetObject(bucket, "key", s3.GetVersionID("3HL4kqtJlcpXroDTDmJ"))
+	_, headErr := fake.HeadObject(bucket, "key", s3.HeadVersionID("3HL4kqtJlcpXroDTDmJ"))
+	body, getNullErr := fake.GetObject(bucket, "key", s3.GetVersionID("null"))
+	data, _ := ioutil.ReadAll(body)
+	_, headNullErr := fake.HeadObject(bucket, "key", s3.HeadVersionID("null"))
+
+	// then
+	assert.Equal(t, "NoSuchVersion", getErr.(s3.Error).Code)
+	assert.Equal(t, "NoSuchVersion", headErr.(s3.Error).Code)
+	assert.NoError(t, getNullErr)
+	assert.Equal(t, "abc", string(data))
+	assert.NoError(t, headNullErr)
+}
+
+func TestFake_PutObject_badDigest(t *testing.T) {
+	// given
+	fake := s3test.NewFake(bucket)
+
+	// when
+	err := fake.PutObject(bucket, "key", bytes.NewReader([]byte("abc")), func(in *awss3.PutObjectInput) {
+		in.ContentMD5 = aws.String("1B2M2Y8AsgTpgAmY7PhCfg==")
+	})
+
+	// then
+	assert.True(t, err.(s3.Error).ChecksumMismatch())
+}
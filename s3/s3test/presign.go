@@ -0,0 +1,103 @@
+package s3test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	signingAlgorithm = "AWS4-HMAC-SHA256"
+	signingTimeFmt   = "20060102T150405Z"
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+)
+
+var (
+	errAccessDenied          = &apiError{http.StatusForbidden, "AccessDenied", "Request has expired"}
+	errAuthorizationQuery    = &apiError{http.StatusBadRequest, "AuthorizationQueryParametersError", "Query-string authentication version 4 requires the X-Amz-Algorithm, X-Amz-Credential, X-Amz-Signature, X-Amz-Date, X-Amz-SignedHeaders, and X-Amz-Expires parameters."}
+	errInvalidAccessKeyID    = &apiError{http.StatusForbidden, "InvalidAccessKeyId", "The AWS Access Key Id you provided does not exist in our records."}
+	errSignatureDoesNotMatch = &apiError{http.StatusForbidden, "SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided."}
+)
+
+// verifyPresigned checks SigV4 query string signature of the request the same way S3 does for presigned URLs.
+func (h *Handler) verifyPresigned(r *http.Request) *apiError {
+	query := r.URL.Query()
+	if query.Get("X-Amz-Algorithm") != signingAlgorithm {
+		return errAuthorizationQuery
+	}
+
+	credential := strings.Split(query.Get("X-Amz-Credential"), "/")
+	if len(credential) != 5 {
+		return errAuthorizationQuery
+	}
+	secret, ok := h.credentials[credential[0]]
+	if !ok {
+		return errInvalidAccessKeyID
+	}
+
+	signedAt, err := time.Parse(signingTimeFmt, query.Get("X-Amz-Date"))
+	if err != nil {
+		return errAuthorizationQuery
+	}
+	expires, err := strconv.Atoi(query.Get("X-Amz-Expires"))
+	if err != nil || expires < 0 {
+		return errAuthorizationQuery
+	}
+	if h.now().After(signedAt.Add(time.Duration(expires) * time.Second)) {
+		return errAccessDenied
+	}
+
+	signature := query.Get("X-Amz-Signature")
+	query.Del("X-Amz-Signature")
+
+	signedHeaders := strings.Split(query.Get("X-Amz-SignedHeaders"), ";")
+	sort.Strings(signedHeaders)
+	var headers strings.Builder
+	for _, name := range signedHeaders {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		headers.WriteString(name + ":" + strings.Join(strings.Fields(value), " ") + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		r.URL.EscapedPath(),
+		strings.Replace(query.Encode(), "+", "%20", -1),
+		headers.String(),
+		strings.Join(signedHeaders, ";"),
+		unsignedPayload,
+	}, "\n")
+
+	scope := strings.Join(credential[1:], "/")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		signingAlgorithm,
+		query.Get("X-Amz-Date"),
+		scope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := []byte("AWS4" + secret)
+	for _, part := range credential[1:] {
+		key = hmacSHA256(key, part)
+	}
+	expected := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errSignatureDoesNotMatch
+	}
+	return nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,744 @@
+package s3test
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	goawss3 "github.com/Ryanair/goaws/s3"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+const (
+	xmlns            = "http://s3.amazonaws.com/doc/2006-03-01/"
+	metaHeaderPrefix = "X-Amz-Meta-"
+	defaultMaxKeys   = 1000
+)
+
+// Handler serves the subset of S3 REST API used by goaws: bucket create/head/delete, object
+// put/get/head/delete/copy, listing (v1 and v2) and multipart uploads. Only path-style requests are supported,
+// so clients have to be created with s3.PathStyle option. Presigned URLs are verified against configured
+// credentials, requests signed with Authorization header are accepted without verification.
+type Handler struct {
+	backend     Backend
+	credentials map[string]string
+	now         func() time.Time
+
+	mu      sync.Mutex
+	uploads map[string]*multipartUpload
+}
+
+type multipartUpload struct {
+	bucket string
+	key    string
+	object *Object
+	parts  map[int64]*Object
+}
+
+func NewHandler(backend Backend, options ...func(*Handler)) *Handler {
+	h := &Handler{
+		backend:     backend,
+		credentials: make(map[string]string),
+		now:         time.Now,
+		uploads:     make(map[string]*multipartUpload),
+	}
+	for _, opt := range options {
+		opt(h)
+	}
+	return h
+}
+
+// NewServer starts a local S3 server, it has to be closed by the caller.
+func NewServer(backend Backend, options ...func(*Handler)) *httptest.Server {
+	return httptest.NewServer(NewHandler(backend, options...))
+}
+
+// Credentials registers access key used to verify presigned URLs.
+func Credentials(accessKeyID, secretAccessKey string) func(*Handler) {
+	return func(h *Handler) {
+		h.credentials[accessKeyID] = secretAccessKey
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if query := r.URL.Query(); query.Get("X-Amz-Signature") != "" {
+		if err := h.verifyPresigned(r); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		for k := range query {
+			if strings.HasPrefix(k, "X-Amz-") {
+				query.Del(k)
+			}
+		}
+		r.URL.RawQuery = query.Encode()
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	if path == "" {
+		writeError(w, r, errNotImplemented)
+		return
+	}
+
+	parts := strings.SplitN(path, "/", 2)
+	if len(parts) == 1 || parts[1] == "" {
+		h.serveBucket(w, r, parts[0])
+		return
+	}
+	h.serveObject(w, r, parts[0], parts[1])
+}
+
+func (h *Handler) serveBucket(w http.ResponseWriter, r *http.Request, bucket string) {
+	query := r.URL.Query()
+	switch {
+	case r.Method == http.MethodPut && len(query) == 0:
+		if err := h.backend.CreateBucket(bucket); err != nil {
+			writeError(w, r, backendError(err))
+			return
+		}
+		w.Header().Set("Location", "/"+bucket)
+	case r.Method == http.MethodHead:
+		if !h.backend.HasBucket(bucket) {
+			writeError(w, r, errNoSuchBucket)
+		}
+	case r.Method == http.MethodDelete && len(query) == 0:
+		if err := h.backend.DeleteBucket(bucket); err != nil {
+			writeError(w, r, backendError(err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && isListRequest(query):
+		h.listObjects(w, r, bucket)
+	default:
+		writeError(w, r, errNotImplemented)
+	}
+}
+
+func (h *Handler) serveObject(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	query := r.URL.Query()
+	_, uploadID := query["uploadId"]
+	_, uploads := query["uploads"]
+
+	switch {
+	case r.Method == http.MethodPut && uploadID && query.Get("partNumber") != "":
+		h.uploadPart(w, r, query.Get("uploadId"), query.Get("partNumber"))
+	case r.Method == http.MethodPut && len(query) == 0 && r.Header.Get("X-Amz-Copy-Source") != "":
+		h.copyObject(w, r, bucket, key)
+	case r.Method == http.MethodPut && len(query) == 0:
+		h.putObject(w, r, bucket, key)
+	case (r.Method == http.MethodGet || r.Method == http.MethodHead) && len(query) == 0:
+		h.getObject(w, r, bucket, key)
+	case r.Method == http.MethodDelete && uploadID:
+		h.abortUpload(w, r, query.Get("uploadId"))
+	case r.Method == http.MethodDelete && len(query) == 0:
+		if err := h.backend.DeleteObject(bucket, key); err != nil {
+			writeError(w, r, backendError(err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPost && uploads:
+		h.createUpload(w, r, bucket, key)
+	case r.Method == http.MethodPost && uploadID:
+		h.completeUpload(w, r, query.Get("uploadId"))
+	default:
+		writeError(w, r, errNotImplemented)
+	}
+}
+
+func (h *Handler) putObject(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	obj, apiErr := h.objectFromRequest(r)
+	if apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+
+	data, apiErr := readBody(r)
+	if apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+	obj.Data = data
+	obj.ETag = etag(data)
+
+	if err := h.backend.PutObject(bucket, key, obj); err != nil {
+		writeError(w, r, backendError(err))
+		return
+	}
+	w.Header().Set("ETag", obj.ETag)
+}
+
+func (h *Handler) getObject(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	obj, err := h.backend.GetObject(bucket, key)
+	if err != nil {
+		writeError(w, r, backendError(err))
+		return
+	}
+
+	in := &s3.GetObjectInput{}
+	if v := r.Header.Get("If-Match"); v != "" {
+		in.IfMatch = aws.String(v)
+	}
+	if v := r.Header.Get("If-None-Match"); v != "" {
+		in.IfNoneMatch = aws.String(v)
+	}
+	if t, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil {
+		in.IfModifiedSince = aws.Time(t)
+	}
+	if t, err := http.ParseTime(r.Header.Get("If-Unmodified-Since")); err == nil {
+		in.IfUnmodifiedSince = aws.Time(t)
+	}
+	switch checkConditions(obj, in) {
+	case goawss3.ErrCodeNotModified:
+		w.Header().Set("ETag", obj.ETag)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	case goawss3.ErrCodePreconditionFailed:
+		writeError(w, r, errPreconditionFailed)
+		return
+	}
+
+	writeObjectHeaders(w, obj)
+
+	data, status := obj.Data, http.StatusOK
+	if rangeHeader := r.Header.Get("Range"); rangeHeader != "" {
+		start, end, ok := parseRange(rangeHeader, int64(len(data)))
+		if !ok {
+			writeError(w, r, errInvalidRange)
+			return
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+		data, status = data[start:end+1], http.StatusPartialContent
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(data)
+	}
+}
+
+func (h *Handler) copyObject(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	srcBucket, srcKey, ok := parseCopySource(r.Header.Get("X-Amz-Copy-Source"))
+	if !ok {
+		writeError(w, r, errInvalidArgument)
+		return
+	}
+
+	src, err := h.backend.GetObject(srcBucket, srcKey)
+	if err != nil {
+		writeError(w, r, backendError(err))
+		return
+	}
+
+	dst, apiErr := h.objectFromRequest(r)
+	if apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+	if r.Header.Get("X-Amz-Metadata-Directive") != s3.MetadataDirectiveReplace {
+		dst.Metadata = src.Metadata
+		dst.ContentType = src.ContentType
+		dst.ContentEncoding = src.ContentEncoding
+		dst.CacheControl = src.CacheControl
+	}
+	if r.Header.Get("X-Amz-Tagging-Directive") != s3.TaggingDirectiveReplace {
+		dst.Tags = src.Tags
+	}
+	if r.Header.Get("X-Amz-Storage-Class") == "" {
+		dst.StorageClass = src.StorageClass
+	}
+	dst.Data, dst.ETag = src.Data, src.ETag
+
+	if err := h.backend.PutObject(bucket, key, dst); err != nil {
+		writeError(w, r, backendError(err))
+		return
+	}
+
+	writeXML(w, http.StatusOK, &copyObjectResult{
+		ETag:         dst.ETag,
+		LastModified: dst.LastModified.UTC().Format(time.RFC3339),
+	})
+}
+
+func (h *Handler) listObjects(w http.ResponseWriter, r *http.Request, bucket string) {
+	query := r.URL.Query()
+	prefix, delimiter := query.Get("prefix"), query.Get("delimiter")
+
+	maxKeys := defaultMaxKeys
+	if v := query.Get("max-keys"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, r, errInvalidArgument)
+			return
+		}
+		maxKeys = n
+	}
+
+	v2 := query.Get("list-type") == "2"
+	after := query.Get("marker")
+	if v2 {
+		after = query.Get("start-after")
+		if token := query.Get("continuation-token"); token != "" {
+			decoded, err := base64.StdEncoding.DecodeString(token)
+			if err != nil {
+				writeError(w, r, errInvalidArgument)
+				return
+			}
+			after = string(decoded)
+		}
+	}
+
+	keys, err := h.backend.ListKeys(bucket, prefix)
+	if err != nil {
+		writeError(w, r, backendError(err))
+		return
+	}
+
+	result := &listBucketResult{
+		Xmlns:     xmlns,
+		Name:      bucket,
+		Prefix:    prefix,
+		Delimiter: delimiter,
+		MaxKeys:   maxKeys,
+	}
+	if v2 {
+		result.StartAfter = query.Get("start-after")
+		result.ContinuationToken = query.Get("continuation-token")
+	} else {
+		result.Marker = aws.String(query.Get("marker"))
+	}
+
+	seenPrefixes := make(map[string]bool)
+	var last string
+	for _, k := range keys {
+		if k <= after {
+			continue
+		}
+		if delimiter != "" {
+			if i := strings.Index(k[len(prefix):], delimiter); i >= 0 {
+				commonPrefix := k[:len(prefix)+i+len(delimiter)]
+				if seenPrefixes[commonPrefix] || commonPrefix <= after {
+					continue
+				}
+				if len(result.Contents)+len(result.CommonPrefixes) == maxKeys {
+					result.IsTruncated = true
+					break
+				}
+				seenPrefixes[commonPrefix] = true
+				result.CommonPrefixes = append(result.CommonPrefixes, commonPrefixEntry{Prefix: commonPrefix})
+				last = commonPrefix
+				continue
+			}
+		}
+
+		if len(result.Contents)+len(result.CommonPrefixes) == maxKeys {
+			result.IsTruncated = true
+			break
+		}
+		obj, err := h.backend.GetObject(bucket, k)
+		if err != nil {
+			continue
+		}
+		result.Contents = append(result.Contents, contentsEntry{
+			Key:          k,
+			LastModified: obj.LastModified.UTC().Format(time.RFC3339),
+			ETag:         obj.ETag,
+			Size:         len(obj.Data),
+			StorageClass: obj.StorageClass,
+		})
+		last = k
+	}
+
+	result.KeyCount = len(result.Contents) + len(result.CommonPrefixes)
+	if result.IsTruncated {
+		if v2 {
+			result.NextContinuationToken = base64.StdEncoding.EncodeToString([]byte(last))
+		} else {
+			result.NextMarker = last
+		}
+	}
+	if !v2 {
+		result.KeyCount = 0
+	}
+
+	writeXML(w, http.StatusOK, result)
+}
+
+func (h *Handler) createUpload(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	if !h.backend.HasBucket(bucket) {
+		writeError(w, r, errNoSuchBucket)
+		return
+	}
+
+	obj, apiErr := h.objectFromRequest(r)
+	if apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+
+	id := newUploadID()
+	h.mu.Lock()
+	h.uploads[id] = &multipartUpload{bucket: bucket, key: key, object: obj, parts: make(map[int64]*Object)}
+	h.mu.Unlock()
+
+	writeXML(w, http.StatusOK, &initiateMultipartUploadResult{Bucket: bucket, Key: key, UploadID: id})
+}
+
+func (h *Handler) uploadPart(w http.ResponseWriter, r *http.Request, uploadID, partNumber string) {
+	n, err := strconv.ParseInt(partNumber, 10, 64)
+	if err != nil || n < 1 || n > 10000 {
+		writeError(w, r, errInvalidArgument)
+		return
+	}
+
+	data, apiErr := readBody(r)
+	if apiErr != nil {
+		writeError(w, r, apiErr)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	upload, ok := h.uploads[uploadID]
+	if !ok {
+		writeError(w, r, errNoSuchUpload)
+		return
+	}
+	part := &Object{Data: data, ETag: etag(data)}
+	upload.parts[n] = part
+
+	w.Header().Set("ETag", part.ETag)
+}
+
+func (h *Handler) completeUpload(w http.ResponseWriter, r *http.Request, uploadID string) {
+	var req completeMultipartUpload
+	if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, errMalformedXML)
+		return
+	}
+
+	h.mu.Lock()
+	upload, ok := h.uploads[uploadID]
+	if !ok {
+		h.mu.Unlock()
+		writeError(w, r, errNoSuchUpload)
+		return
+	}
+
+	var (
+		data    []byte
+		digests []byte
+		prev    int64
+	)
+	for _, p := range req.Parts {
+		if p.PartNumber <= prev {
+			h.mu.Unlock()
+			writeError(w, r, errInvalidPartOrder)
+			return
+		}
+		prev = p.PartNumber
+
+		part, ok := upload.parts[p.PartNumber]
+		if !ok || strings.Trim(p.ETag, `"`) != strings.Trim(part.ETag, `"`) {
+			h.mu.Unlock()
+			writeError(w, r, errInvalidPart)
+			return
+		}
+		sum := md5.Sum(part.Data)
+		digests = append(digests, sum[:]...)
+		data = append(data, part.Data...)
+	}
+	delete(h.uploads, uploadID)
+	h.mu.Unlock()
+
+	sum := md5.Sum(digests)
+	obj := upload.object
+	obj.Data = data
+	obj.ETag = fmt.Sprintf(`"%s-%d"`, hex.EncodeToString(sum[:]), len(req.Parts))
+
+	if err := h.backend.PutObject(upload.bucket, upload.key, obj); err != nil {
+		writeError(w, r, backendError(err))
+		return
+	}
+
+	writeXML(w, http.StatusOK, &completeMultipartUploadResult{
+		Location: "/" + upload.bucket + "/" + upload.key,
+		Bucket:   upload.bucket,
+		Key:      upload.key,
+		ETag:     obj.ETag,
+	})
+}
+
+func (h *Handler) abortUpload(w http.ResponseWriter, r *http.Request, uploadID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.uploads[uploadID]; !ok {
+		writeError(w, r, errNoSuchUpload)
+		return
+	}
+	delete(h.uploads, uploadID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// objectFromRequest reads object attributes sent as request headers.
+func (h *Handler) objectFromRequest(r *http.Request) (*Object, *apiError) {
+	tags := make(map[string]string)
+	if v := r.Header.Get("X-Amz-Tagging"); v != "" {
+		var err error
+		if tags, err = parseTags(aws.String(v)); err != nil {
+			return nil, errInvalidArgument
+		}
+	}
+
+	meta := make(map[string]string)
+	for k, v := range r.Header {
+		if strings.HasPrefix(k, metaHeaderPrefix) {
+			meta[http.CanonicalHeaderKey(strings.TrimPrefix(k, metaHeaderPrefix))] = v[0]
+		}
+	}
+
+	return &Object{
+		ContentType:     headerOr(r, "Content-Type", defaultContentType),
+		ContentEncoding: r.Header.Get("Content-Encoding"),
+		CacheControl:    r.Header.Get("Cache-Control"),
+		LastModified:    h.now().UTC().Truncate(time.Second),
+		StorageClass:    headerOr(r, "X-Amz-Storage-Class", s3.StorageClassStandard),
+		Metadata:        meta,
+		Tags:            tags,
+	}, nil
+}
+
+func readBody(r *http.Request) ([]byte, *apiError) {
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, errIncompleteBody
+	}
+
+	if v := r.Header.Get("Content-Md5"); v != "" {
+		sum := md5.Sum(data)
+		if base64.StdEncoding.EncodeToString(sum[:]) != v {
+			return nil, errBadDigest
+		}
+	}
+	return data, nil
+}
+
+func writeObjectHeaders(w http.ResponseWriter, obj *Object) {
+	header := w.Header()
+	header.Set("ETag", obj.ETag)
+	header.Set("Last-Modified", obj.LastModified.UTC().Format(http.TimeFormat))
+	header.Set("Content-Type", obj.ContentType)
+	header.Set("Accept-Ranges", "bytes")
+	if obj.ContentEncoding != "" {
+		header.Set("Content-Encoding", obj.ContentEncoding)
+	}
+	if obj.CacheControl != "" {
+		header.Set("Cache-Control", obj.CacheControl)
+	}
+	if obj.StorageClass != s3.StorageClassStandard {
+		header.Set("X-Amz-Storage-Class", obj.StorageClass)
+	}
+	if len(obj.Tags) > 0 {
+		header.Set("X-Amz-Tagging-Count", strconv.Itoa(len(obj.Tags)))
+	}
+
+	keys := make([]string, 0, len(obj.Metadata))
+	for k := range obj.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		header.Set(metaHeaderPrefix+k, obj.Metadata[k])
+	}
+}
+
+func parseCopySource(source string) (bucket, key string, ok bool) {
+	if i := strings.Index(source, "?"); i >= 0 {
+		source = source[:i]
+	}
+	source, err := url.PathUnescape(strings.TrimPrefix(source, "/"))
+	if err != nil {
+		return "", "", false
+	}
+
+	parts := strings.SplitN(source, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+func isListRequest(query url.Values) bool {
+	for k := range query {
+		switch k {
+		case "list-type", "prefix", "delimiter", "max-keys", "marker", "start-after", "continuation-token",
+			"encoding-type", "fetch-owner":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func headerOr(r *http.Request, name, def string) string {
+	if v := r.Header.Get(name); v != "" {
+		return v
+	}
+	return def
+}
+
+func newUploadID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func writeXML(w http.ResponseWriter, status int, v interface{}) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	if err := xml.NewEncoder(&buf).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = w.Write(buf.Bytes())
+}
+
+type listBucketResult struct {
+	XMLName               xml.Name            `xml:"ListBucketResult"`
+	Xmlns                 string              `xml:"xmlns,attr"`
+	Name                  string              `xml:"Name"`
+	Prefix                string              `xml:"Prefix"`
+	Delimiter             string              `xml:"Delimiter,omitempty"`
+	Marker                *string             `xml:"Marker"`
+	NextMarker            string              `xml:"NextMarker,omitempty"`
+	StartAfter            string              `xml:"StartAfter,omitempty"`
+	ContinuationToken     string              `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string              `xml:"NextContinuationToken,omitempty"`
+	KeyCount              int                 `xml:"KeyCount,omitempty"`
+	MaxKeys               int                 `xml:"MaxKeys"`
+	IsTruncated           bool                `xml:"IsTruncated"`
+	Contents              []contentsEntry     `xml:"Contents"`
+	CommonPrefixes        []commonPrefixEntry `xml:"CommonPrefixes"`
+}
+
+type contentsEntry struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int    `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+type commonPrefixEntry struct {
+	Prefix string `xml:"Prefix"`
+}
+
+type copyObjectResult struct {
+	XMLName      xml.Name `xml:"CopyObjectResult"`
+	ETag         string   `xml:"ETag"`
+	LastModified string   `xml:"LastModified"`
+}
+
+type initiateMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	UploadID string   `xml:"UploadId"`
+}
+
+type completeMultipartUpload struct {
+	Parts []struct {
+		PartNumber int64  `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+	} `xml:"Part"`
+}
+
+type completeMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"CompleteMultipartUploadResult"`
+	Location string   `xml:"Location"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	ETag     string   `xml:"ETag"`
+}
+
+type apiError struct {
+	status  int
+	code    string
+	message string
+}
+
+var (
+	errNoSuchBucket       = &apiError{http.StatusNotFound, s3.ErrCodeNoSuchBucket, "The specified bucket does not exist"}
+	errNoSuchKey          = &apiError{http.StatusNotFound, s3.ErrCodeNoSuchKey, "The specified key does not exist."}
+	errNoSuchUpload       = &apiError{http.StatusNotFound, s3.ErrCodeNoSuchUpload, "The specified upload does not exist."}
+	errBucketExists       = &apiError{http.StatusConflict, s3.ErrCodeBucketAlreadyOwnedByYou, "Your previous request to create the named bucket succeeded and you already own it."}
+	errBucketNotEmpty     = &apiError{http.StatusConflict, "BucketNotEmpty", "The bucket you tried to delete is not empty"}
+	errInvalidBucketName  = &apiError{http.StatusBadRequest, "InvalidBucketName", "The specified bucket is not valid."}
+	errBadDigest          = &apiError{http.StatusBadRequest, goawss3.ErrCodeBadDigest, "The Content-MD5 you specified did not match what we received."}
+	errIncompleteBody     = &apiError{http.StatusBadRequest, "IncompleteBody", "You did not provide the number of bytes specified by the Content-Length HTTP header."}
+	errInvalidArgument    = &apiError{http.StatusBadRequest, "InvalidArgument", "Invalid Argument"}
+	errInvalidPart        = &apiError{http.StatusBadRequest, "InvalidPart", "One or more of the specified parts could not be found."}
+	errInvalidPartOrder   = &apiError{http.StatusBadRequest, "InvalidPartOrder", "The list of parts was not in ascending order."}
+	errMalformedXML       = &apiError{http.StatusBadRequest, "MalformedXML", "The XML you provided was not well-formed."}
+	errInvalidRange       = &apiError{http.StatusRequestedRangeNotSatisfiable, errCodeInvalidRange, "The requested range is not satisfiable"}
+	errPreconditionFailed = &apiError{http.StatusPreconditionFailed, goawss3.ErrCodePreconditionFailed, "At least one of the pre-conditions you specified did not hold"}
+	errNotImplemented     = &apiError{http.StatusNotImplemented, "NotImplemented", "A header or query you provided implies functionality that is not implemented."}
+	errInternal           = &apiError{http.StatusInternalServerError, "InternalError", "We encountered an internal error. Please try again."}
+)
+
+func backendError(err error) *apiError {
+	switch err {
+	case ErrNoSuchBucket:
+		return errNoSuchBucket
+	case ErrNoSuchKey:
+		return errNoSuchKey
+	case ErrBucketExists:
+		return errBucketExists
+	case ErrBucketNotEmpty:
+		return errBucketNotEmpty
+	case ErrInvalidBucketName:
+		return errInvalidBucketName
+	}
+	return errInternal
+}
+
+// writeError writes S3 XML error document, HEAD responses carry only the status code just like S3 does.
+func writeError(w http.ResponseWriter, r *http.Request, err *apiError) {
+	if r.Method == http.MethodHead {
+		w.WriteHeader(err.status)
+		return
+	}
+
+	writeXML(w, err.status, &errorResponse{
+		Code:     err.code,
+		Message:  err.message,
+		Resource: r.URL.Path,
+	})
+}
+
+type errorResponse struct {
+	XMLName  xml.Name `xml:"Error"`
+	Code     string   `xml:"Code"`
+	Message  string   `xml:"Message"`
+	Resource string   `xml:"Resource"`
+}
@@ -0,0 +1,415 @@
+package s3test_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ryanair/goaws"
+	"github.com/Ryanair/goaws/s3"
+	"github.com/Ryanair/goaws/s3/s3test"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	accessKeyID     = "AKIDEXAMPLE"
+	secretAccessKey = "secret"
+)
+
+func newServerClient(t *testing.T, url string) *s3.Client {
+	cfg, err := goaws.NewConfig(goaws.Region("eu-west-1"), goaws.Credentials(accessKeyID, secretAccessKey, ""))
+	require.NoError(t, err)
+	return s3.NewClient(cfg, s3.Endpoint(url), s3.PathStyle())
+}
+
+func TestServer_objects(t *testing.T) {
+	// given
+	srv := s3test.NewServer(s3test.NewMemoryBackend(bucket))
+	defer srv.Close()
+	cli := newServerClient(t, srv.URL)
+	meta := map[string]*string{"filename": aws.String("HappyFace.jpg")}
+
+	// when
+	putErr := cli.PutObject(bucket, "dir/some key", bytes.NewReader([]byte("abcdef")), s3.Metadata(meta))
+	copyErr := cli.CopyObject(bucket, "dir/some key", bucket, "other/key")
+	out, getErr := cli.GetObject(bucket, "dir/some key", s3.Range(1, 3))
+	data, _ := ioutil.ReadAll(out)
+	head, headErr := cli.HeadObject(bucket, "other/key")
+	list, listErr := cli.ListObjects(bucket, "dir/")
+	deleteErr := cli.DeleteObject(bucket, "dir/some key")
+	_, missingErr := cli.HeadObject(bucket, "dir/some key")
+
+	// then
+	assert.NoError(t, putErr)
+	assert.NoError(t, copyErr)
+	assert.NoError(t, getErr)
+	assert.NoError(t, headErr)
+	assert.NoError(t, listErr)
+	assert.NoError(t, deleteErr)
+	assert.Equal(t, "bcd", string(data))
+	assert.Equal(t, map[string]*string{"Filename": aws.String("HappyFace.jpg")}, head.Metadata)
+	assert.Equal(t, int64(6), *head.ContentLength)
+	if assert.Len(t, list, 1) {
+		assert.Equal(t, "dir/some key", *list[0].Key)
+	}
+	assert.True(t, missingErr.(s3.Error).ResourceNotFound())
+}
+
+func TestServer_errors(t *testing.T) {
+	// given
+	srv := s3test.NewServer(s3test.NewMemoryBackend(bucket))
+	defer srv.Close()
+	cli := newServerClient(t, srv.URL)
+	_ = cli.PutObject(bucket, "key", bytes.NewReader([]byte("abc")))
+
+	// when
+	_, keyErr := cli.GetObject(bucket, "missing")
+	_, bucketErr := cli.GetObject("missing-bucket", "key")
+	_, notModifiedErr := cli.GetObject(bucket, "key", s3.IfNoneMatch(`"900150983cd24fb0d6963f7d28e17f72"`))
+	_, preconditionErr := cli.GetObject(bucket, "key", s3.IfMatch(`"other"`))
+	deleteBucketErr := cli.DeleteBucket(bucket, false)
+
+	// then
+	assert.True(t, keyErr.(s3.Error).KeyNotFound())
+	assert.True(t, bucketErr.(s3.Error).BucketNotFound())
+	assert.True(t, notModifiedErr.(s3.Error).NotModified())
+	assert.True(t, preconditionErr.(s3.Error).PreconditionFailed())
+	assert.Error(t, deleteBucketErr)
+}
+
+func TestServer_buckets(t *testing.T) {
+	// given
+	srv := s3test.NewServer(s3test.NewMemoryBackend())
+	defer srv.Close()
+	cli := newServerClient(t, srv.URL)
+
+	// when
+	createErr := cli.CreateBucket(bucket)
+	exists, existsErr := cli.BucketExists(bucket)
+	deleteErr := cli.DeleteBucket(bucket, false)
+	existsAfterDelete, _ := cli.BucketExists(bucket)
+
+	// then
+	assert.NoError(t, createErr)
+	assert.NoError(t, existsErr)
+	assert.NoError(t, deleteErr)
+	assert.True(t, exists)
+	assert.False(t, existsAfterDelete)
+}
+
+func TestServer_multipartUpload(t *testing.T) {
+	// given
+	srv := s3test.NewServer(s3test.NewMemoryBackend(bucket))
+	defer srv.Close()
+	sess := session.Must(session.NewSession(&aws.Config{
+		Region:           aws.String("eu-west-1"),
+		Endpoint:         aws.String(srv.URL),
+		Credentials:      credentials.NewStaticCredentials(accessKeyID, secretAccessKey, ""),
+		S3ForcePathStyle: aws.Bool(true),
+	}))
+	data := bytes.Repeat([]byte("0123456789"), 1200*1024)
+
+	// when
+	uploader := s3manager.NewUploader(sess, func(u *s3manager.Uploader) {
+		u.PartSize = s3manager.MinUploadPartSize
+	})
+	_, uploadErr := uploader.Upload(&s3manager.UploadInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String("large"),
+		Body:   bytes.NewReader(data),
+	})
+	cli := newServerClient(t, srv.URL)
+	obj, getErr := cli.GetObject(bucket, "large")
+	saved, _ := ioutil.ReadAll(obj)
+	head, headErr := cli.HeadObject(bucket, "large")
+
+	// then
+	assert.NoError(t, uploadErr)
+	assert.NoError(t, getErr)
+	assert.NoError(t, headErr)
+	assert.True(t, strings.HasSuffix(*head.ETag, `-3"`))
+	assert.Equal(t, data, saved)
+}
+
+func TestServer_presignedURL(t *testing.T) {
+	// given
+	srv := s3test.NewServer(s3test.NewMemoryBackend(bucket), s3test.Credentials(accessKeyID, secretAccessKey))
+	defer srv.Close()
+	cli := newServerClient(t, srv.URL)
+
+	cfg, _ := goaws.NewConfig(goaws.Region("eu-west-1"), goaws.Credentials(accessKeyID, "wrong", ""))
+	wrongCli := s3.NewClient(cfg, s3.Endpoint(srv.URL), s3.PathStyle())
+
+	put := func(url string) int {
+		req, _ := http.NewRequest(http.MethodPut, url, strings.NewReader("abc"))
+		req.Header.Set("Content-Type", "text/plain")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// when
+	url, urlErr := cli.GeneratePutURL(bucket, "uploaded key", "text/plain", time.Minute)
+	wrongURL, _ := wrongCli.GeneratePutURL(bucket, "uploaded key", "text/plain", time.Minute)
+	status := put(url)
+	wrongStatus := put(wrongURL)
+	head, headErr := cli.HeadObject(bucket, "uploaded key")
+
+	// then
+	assert.NoError(t, urlErr)
+	assert.NoError(t, headErr)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, http.StatusForbidden, wrongStatus)
+	assert.Equal(t, "text/plain", *head.ContentType)
+}
+
+func TestServer_dirBackend(t *testing.T) {
+	// given
+	dir, err := ioutil.TempDir("", "s3test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	backend, err := s3test.NewDirBackend(dir)
+	require.NoError(t, err)
+	srv := s3test.NewServer(backend)
+	defer srv.Close()
+	cli := newServerClient(t, srv.URL)
+
+	// when
+	createErr := cli.CreateBucket(bucket)
+	putErr := cli.PutObject(bucket, ".hidden/key", bytes.NewReader([]byte("abc")))
+	list, listErr := cli.ListObjects(bucket, "")
+	reopened, _ := s3test.NewDirBackend(dir)
+	obj, getErr := reopened.GetObject(bucket, ".hidden/key")
+
+	// then
+	assert.NoError(t, createErr)
+	assert.NoError(t, putErr)
+	assert.NoError(t, listErr)
+	if assert.Len(t, list, 1) {
+		assert.Equal(t, ".hidden/key", *list[0].Key)
+	}
+	assert.NoError(t, getErr)
+	assert.Equal(t, "abc", string(obj.Data))
+}
+
+func TestServer_checksum(t *testing.T) {
+	// given
+	backend := s3test.NewMemoryBackend(bucket)
+	srv := s3test.NewServer(backend)
+	defer srv.Close()
+	cli := newServerClient(t, srv.URL)
+	data := bytes.Repeat([]byte("0123456789"), 1200*1024)
+
+	isChecksumMismatch := func(err error) bool {
+		e, ok := err.(s3.Error)
+		return ok && e.ChecksumMismatch()
+	}
+
+	// when
+	putErr := cli.PutObject(bucket, "small", bytes.NewReader([]byte("abc")), s3.Checksum())
+	uploadErr := cli.UploadObject(bucket, "large", bytes.NewReader(data))
+
+	obj, _ := backend.GetObject(bucket, "small")
+	obj.Data = []byte("abd")
+	_ = backend.PutObject(bucket, "small", obj)
+	corrupted, getErr := cli.GetObject(bucket, "small")
+	_, readErr := ioutil.ReadAll(corrupted)
+
+	large, _ := cli.GetObject(bucket, "large")
+	_, largeReadErr := ioutil.ReadAll(large)
+
+	// then
+	assert.NoError(t, putErr)
+	assert.NoError(t, uploadErr)
+	assert.NoError(t, getErr)
+	assert.NoError(t, largeReadErr)
+	assert.True(t, isChecksumMismatch(readErr))
+}
+
+func TestServer_sync(t *testing.T) {
+	// given
+	srv := s3test.NewServer(s3test.NewMemoryBackend(bucket))
+	defer srv.Close()
+	cli := newServerClient(t, srv.URL)
+
+	src, err := ioutil.TempDir("", "s3test")
+	require.NoError(t, err)
+	defer os.RemoveAll(src)
+	dst, err := ioutil.TempDir("", "s3test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dst)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "css"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(src, "index.html"), []byte("<html></html>"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(src, "css", "site.css"), []byte("body {}"), 0644))
+	_ = cli.PutObject(bucket, "site/stale.txt", bytes.NewReader([]byte("stale")))
+
+	// when
+	planned, planErr := cli.Sync(src, bucket, "site", s3.SyncDeleteExtraneous(), s3.SyncDryRun())
+	_, plannedHeadErr := cli.HeadObject(bucket, "site/index.html")
+	uploaded, syncErr := cli.Sync(src, bucket, "site", s3.SyncDeleteExtraneous())
+	head, headErr := cli.HeadObject(bucket, "site/index.html")
+	_, staleErr := cli.HeadObject(bucket, "site/stale.txt")
+	unchanged, resyncErr := cli.Sync(src, bucket, "site")
+	downloaded, downErr := cli.SyncToLocal(bucket, "site", dst)
+	css, readErr := ioutil.ReadFile(filepath.Join(dst, "css", "site.css"))
+
+	// then
+	assert.NoError(t, planErr)
+	assert.NoError(t, syncErr)
+	assert.NoError(t, headErr)
+	assert.NoError(t, resyncErr)
+	assert.NoError(t, downErr)
+	assert.NoError(t, readErr)
+	assert.Equal(t, []*s3.SyncAction{
+		{Type: s3.SyncUpload, Key: "site/css/site.css", Path: filepath.Join(src, "css", "site.css")},
+		{Type: s3.SyncUpload, Key: "site/index.html", Path: filepath.Join(src, "index.html")},
+		{Type: s3.SyncDelete, Key: "site/stale.txt"},
+	}, planned)
+	assert.Equal(t, planned, uploaded)
+	assert.True(t, plannedHeadErr.(s3.Error).ResourceNotFound())
+	assert.True(t, staleErr.(s3.Error).ResourceNotFound())
+	assert.Equal(t, "text/html; charset=utf-8", *head.ContentType)
+	assert.Empty(t, unchanged)
+	assert.Len(t, downloaded, 2)
+	assert.Equal(t, "body {}", string(css))
+}
+
+func TestServer_syncToLocal(t *testing.T) {
+	// given
+	backend := s3test.NewMemoryBackend(bucket)
+	srv := s3test.NewServer(backend)
+	defer srv.Close()
+	cli := newServerClient(t, srv.URL)
+
+	dst, err := ioutil.TempDir("", "s3test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dst)
+
+	_ = cli.PutObject(bucket, "site/app.js", bytes.NewReader(bytes.Repeat([]byte("var a = 1;"), 100)), s3.Gzip())
+	_ = backend.PutObject(bucket, "escape/../../evil.txt", &s3test.Object{Data: []byte("evil"), LastModified: time.Now()})
+
+	// when
+	downloaded, downErr := cli.SyncToLocal(bucket, "site", dst)
+	unchanged, resyncErr := cli.SyncToLocal(bucket, "site", dst)
+	_, escapeErr := cli.SyncToLocal(bucket, "escape", filepath.Join(dst, "escape"))
+	_, evilErr := os.Stat(filepath.Join(filepath.Dir(dst), "evil.txt"))
+
+	// then
+	assert.NoError(t, downErr)
+	assert.NoError(t, resyncErr)
+	assert.Len(t, downloaded, 1)
+	assert.Empty(t, unchanged)
+	assert.Error(t, escapeErr)
+	assert.True(t, os.IsNotExist(evilErr))
+}
+
+func TestServer_syncToLocal_currentDir(t *testing.T) {
+	// given
+	srv := s3test.NewServer(s3test.NewMemoryBackend(bucket))
+	defer srv.Close()
+	cli := newServerClient(t, srv.URL)
+
+	dst, err := ioutil.TempDir("", "s3test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dst)
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dst))
+	defer os.Chdir(wd)
+
+	_ = cli.PutObject(bucket, "site/css/site.css", bytes.NewReader([]byte("body {}")))
+
+	// when
+	downloaded, downErr := cli.SyncToLocal(bucket, "site", ".")
+	css, readErr := ioutil.ReadFile(filepath.Join(dst, "css", "site.css"))
+
+	// then
+	assert.NoError(t, downErr)
+	assert.NoError(t, readErr)
+	assert.Equal(t, []*s3.SyncAction{
+		{Type: s3.SyncDownload, Key: "site/css/site.css", Path: filepath.Join("css", "site.css")},
+	}, downloaded)
+	assert.Equal(t, "body {}", string(css))
+}
+
+func TestServer_codecs(t *testing.T) {
+	// given
+	srv := s3test.NewServer(s3test.NewMemoryBackend(bucket))
+	defer srv.Close()
+	cli := newServerClient(t, srv.URL)
+
+	type document struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	records := [][]string{{"name", "count"}, {"goaws", "1"}}
+
+	// when
+	putJSONErr := cli.PutJSON(bucket, "doc.json.gz", &document{Name: "goaws", Count: 1}, s3.Gzip(), s3.Checksum())
+	putCSVErr := cli.PutCSV(bucket, "doc.csv", records)
+	var doc document
+	getJSONErr := cli.GetJSON(bucket, "doc.json.gz", &doc)
+	csvRecords, getCSVErr := cli.GetCSV(bucket, "doc.csv")
+	head, _ := cli.HeadObject(bucket, "doc.json.gz")
+	unmarshalErr := cli.GetJSON(bucket, "doc.csv", &doc)
+
+	// then
+	assert.NoError(t, putJSONErr)
+	assert.NoError(t, putCSVErr)
+	assert.NoError(t, getJSONErr)
+	assert.NoError(t, getCSVErr)
+	assert.Equal(t, document{Name: "goaws", Count: 1}, doc)
+	assert.Equal(t, records, csvRecords)
+	assert.Equal(t, "application/json", *head.ContentType)
+	assert.Equal(t, "gzip", *head.ContentEncoding)
+	assert.True(t, unmarshalErr.(s3.Error).UnmarshallingFailed())
+}
+
+func TestServer_waitForObject(t *testing.T) {
+	// given
+	srv := s3test.NewServer(s3test.NewMemoryBackend(bucket), s3test.Credentials(accessKeyID, secretAccessKey))
+	defer srv.Close()
+	cli := newServerClient(t, srv.URL)
+	url, _ := cli.GeneratePutURL(bucket, "upload", "image/png", time.Minute)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		req, _ := http.NewRequest(http.MethodPut, url, strings.NewReader("png"))
+		req.Header.Set("Content-Type", "image/png")
+		resp, err := http.DefaultClient.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	// when
+	presigned, parseErr := s3.ParsePresignedURL(url)
+	head, waitErr := cli.WaitForObject(bucket, "upload", 5*time.Second, s3.WaitContentType("image/png"),
+		s3.WaitContentLength(3), s3.WaitBackoff(10*time.Millisecond, 50*time.Millisecond))
+	_, mismatchErr := cli.WaitForObject(bucket, "upload", time.Second, s3.WaitContentLength(4))
+	_, timeoutErr := cli.WaitForObject(bucket, "missing", 30*time.Millisecond, s3.WaitBackoff(10*time.Millisecond, 10*time.Millisecond))
+
+	// then
+	assert.NoError(t, parseErr)
+	assert.NoError(t, waitErr)
+	assert.Equal(t, bucket, presigned.Bucket)
+	assert.Equal(t, "upload", presigned.Key)
+	assert.Contains(t, presigned.SignedHeaders, "content-type")
+	assert.Equal(t, int64(3), *head.ContentLength)
+	assert.True(t, mismatchErr.(s3.Error).UnexpectedObject())
+	assert.True(t, timeoutErr.(s3.Error).WaitTimedOut())
+}
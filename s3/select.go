@@ -0,0 +1,210 @@
+package s3
+
+import (
+	"bytes"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+)
+
+const defaultRecordDelimiter = "\n"
+
+type SelectStats struct {
+	BytesScanned   *int64
+	BytesProcessed *int64
+	BytesReturned  *int64
+}
+
+type selectEventStream interface {
+	Events() <-chan s3.SelectObjectContentEventStreamEvent
+	Close() error
+	Err() error
+}
+
+// SelectIterator streams records returned by SelectObjectContent. It has to be closed by the caller.
+type SelectIterator struct {
+	stream    selectEventStream
+	delimiter []byte
+	buf       []byte
+	record    []byte
+	progress  *SelectStats
+	stats     *SelectStats
+	ended     bool
+	closed    bool
+	err       error
+}
+
+// SelectObjectContent runs SQL expression against the object. Input defaults to CSV with a header line and output to
+// JSON Lines, both can be changed with options. Records are split on the output record delimiter, so CSV output with
+// quoted fields containing the delimiter is not supported, JSON output should be used for such data.
+func (c *Client) SelectObjectContent(bucket, key, expression string, options ...func(*s3.SelectObjectContentInput)) (*SelectIterator, error) {
+	input := &s3.SelectObjectContentInput{
+		Bucket:         aws.String(bucket),
+		Key:            aws.String(key),
+		Expression:     aws.String(expression),
+		ExpressionType: aws.String(s3.ExpressionTypeSql),
+		InputSerialization: &s3.InputSerialization{
+			CSV: &s3.CSVInput{FileHeaderInfo: aws.String(s3.FileHeaderInfoUse)},
+		},
+		OutputSerialization: &s3.OutputSerialization{
+			JSON: &s3.JSONOutput{},
+		},
+	}
+	for _, opt := range options {
+		opt(input)
+	}
+
+	out, err := c.s3.SelectObjectContent(input)
+	if err != nil {
+		return nil, wrapErr(err, "select object content failed")
+	}
+
+	return newSelectIterator(out.EventStream, recordDelimiter(input.OutputSerialization)), nil
+}
+
+func newSelectIterator(stream selectEventStream, delimiter string) *SelectIterator {
+	return &SelectIterator{stream: stream, delimiter: []byte(delimiter)}
+}
+
+// Next advances to the next record, it returns false when the stream ended or failed, which is reported by Err.
+func (it *SelectIterator) Next() bool {
+	for {
+		if i := bytes.Index(it.buf, it.delimiter); i >= 0 {
+			it.record, it.buf = it.buf[:i], it.buf[i+len(it.delimiter):]
+			return true
+		}
+		if it.closed {
+			if it.err == nil && len(it.buf) > 0 {
+				it.record, it.buf = it.buf, nil
+				return true
+			}
+			it.record = nil
+			return false
+		}
+
+		event, ok := <-it.stream.Events()
+		if !ok {
+			it.closed = true
+			if err := it.stream.Err(); err != nil {
+				it.err = wrapErr(err, "select object content stream failed")
+			} else if !it.ended {
+				it.err = wrapErrWithCode(errors.New("stream closed before end event"),
+					"select object content stream failed", ErrCodeSelectIncomplete)
+			}
+			continue
+		}
+
+		switch e := event.(type) {
+		case *s3.RecordsEvent:
+			it.buf = append(it.buf, e.Payload...)
+		case *s3.ProgressEvent:
+			it.progress = toSelectStats(e.Details.BytesScanned, e.Details.BytesProcessed, e.Details.BytesReturned)
+		case *s3.StatsEvent:
+			it.stats = toSelectStats(e.Details.BytesScanned, e.Details.BytesProcessed, e.Details.BytesReturned)
+		case *s3.EndEvent:
+			it.ended = true
+		}
+	}
+}
+
+// Record returns the current record without delimiter, it is valid until the next call to Next.
+func (it *SelectIterator) Record() []byte {
+	return it.record
+}
+
+// Progress returns the latest progress, reported only when requested with SelectProgress.
+func (it *SelectIterator) Progress() *SelectStats {
+	return it.progress
+}
+
+// Stats returns statistics sent by S3 just before the end of the stream.
+func (it *SelectIterator) Stats() *SelectStats {
+	return it.stats
+}
+
+func (it *SelectIterator) Err() error {
+	return it.err
+}
+
+func (it *SelectIterator) Close() error {
+	if err := it.stream.Close(); err != nil && it.err == nil {
+		return wrapErr(err, "select object content close failed")
+	}
+	return nil
+}
+
+// SelectCSVInput reads the object as CSV, fileHeaderInfo is one of s3.FileHeaderInfo values.
+func SelectCSVInput(fileHeaderInfo string) func(*s3.SelectObjectContentInput) {
+	return func(in *s3.SelectObjectContentInput) {
+		in.InputSerialization.CSV = &s3.CSVInput{FileHeaderInfo: aws.String(fileHeaderInfo)}
+		in.InputSerialization.JSON = nil
+		in.InputSerialization.Parquet = nil
+	}
+}
+
+// SelectJSONInput reads the object as JSON, jsonType is one of s3.JSONType values.
+func SelectJSONInput(jsonType string) func(*s3.SelectObjectContentInput) {
+	return func(in *s3.SelectObjectContentInput) {
+		in.InputSerialization.CSV = nil
+		in.InputSerialization.JSON = &s3.JSONInput{Type: aws.String(jsonType)}
+		in.InputSerialization.Parquet = nil
+	}
+}
+
+func SelectParquetInput() func(*s3.SelectObjectContentInput) {
+	return func(in *s3.SelectObjectContentInput) {
+		in.InputSerialization.CSV = nil
+		in.InputSerialization.JSON = nil
+		in.InputSerialization.Parquet = &s3.ParquetInput{}
+	}
+}
+
+// SelectCompression sets compression of the object, one of s3.CompressionType values.
+func SelectCompression(compression string) func(*s3.SelectObjectContentInput) {
+	return func(in *s3.SelectObjectContentInput) {
+		in.InputSerialization.CompressionType = aws.String(compression)
+	}
+}
+
+func SelectCSVOutput() func(*s3.SelectObjectContentInput) {
+	return func(in *s3.SelectObjectContentInput) {
+		in.OutputSerialization = &s3.OutputSerialization{CSV: &s3.CSVOutput{}}
+	}
+}
+
+func SelectJSONOutput() func(*s3.SelectObjectContentInput) {
+	return func(in *s3.SelectObjectContentInput) {
+		in.OutputSerialization = &s3.OutputSerialization{JSON: &s3.JSONOutput{}}
+	}
+}
+
+func SelectProgress() func(*s3.SelectObjectContentInput) {
+	return func(in *s3.SelectObjectContentInput) {
+		in.RequestProgress = &s3.RequestProgress{Enabled: aws.Bool(true)}
+	}
+}
+
+func recordDelimiter(out *s3.OutputSerialization) string {
+	var delimiter *string
+	switch {
+	case out == nil:
+	case out.CSV != nil:
+		delimiter = out.CSV.RecordDelimiter
+	case out.JSON != nil:
+		delimiter = out.JSON.RecordDelimiter
+	}
+
+	if d := aws.StringValue(delimiter); d != "" {
+		return d
+	}
+	return defaultRecordDelimiter
+}
+
+func toSelectStats(scanned, processed, returned *int64) *SelectStats {
+	return &SelectStats{
+		BytesScanned:   scanned,
+		BytesProcessed: processed,
+		BytesReturned:  returned,
+	}
+}
@@ -0,0 +1,96 @@
+// +build local ci
+
+package s3
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+type streamMock struct {
+	events chan s3.SelectObjectContentEventStreamEvent
+	err    error
+}
+
+func newStreamMock(err error, events ...s3.SelectObjectContentEventStreamEvent) *streamMock {
+	ch := make(chan s3.SelectObjectContentEventStreamEvent, len(events))
+	for _, e := range events {
+		ch <- e
+	}
+	close(ch)
+	return &streamMock{events: ch, err: err}
+}
+
+func (m *streamMock) Events() <-chan s3.SelectObjectContentEventStreamEvent {
+	return m.events
+}
+
+func (m *streamMock) Close() error {
+	return nil
+}
+
+func (m *streamMock) Err() error {
+	return m.err
+}
+
+func TestSelectIterator_ok(t *testing.T) {
+	// given
+	stream := newStreamMock(nil,
+		&s3.RecordsEvent{Payload: []byte(`{"name":"a"}` + "\n" + `{"na`)},
+		&s3.ContinuationEvent{},
+		&s3.RecordsEvent{Payload: []byte(`me":"b"}` + "\n")},
+		&s3.StatsEvent{Details: &s3.Stats{BytesScanned: aws.Int64(100), BytesProcessed: aws.Int64(100), BytesReturned: aws.Int64(26)}},
+		&s3.EndEvent{},
+	)
+	it := newSelectIterator(stream, defaultRecordDelimiter)
+
+	// when
+	var records []string
+	for it.Next() {
+		records = append(records, string(it.Record()))
+	}
+
+	// then
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []string{`{"name":"a"}`, `{"name":"b"}`}, records)
+	assert.Equal(t, int64(26), *it.Stats().BytesReturned)
+	assert.NoError(t, it.Close())
+}
+
+func TestSelectIterator_incomplete(t *testing.T) {
+	// given
+	stream := newStreamMock(nil, &s3.RecordsEvent{Payload: []byte("a,b\nc,")})
+	it := newSelectIterator(stream, "\n")
+
+	// when
+	var records []string
+	for it.Next() {
+		records = append(records, string(it.Record()))
+	}
+
+	// then
+	assert.Equal(t, []string{"a,b"}, records)
+	assert.True(t, it.Err().(Error).SelectIncomplete())
+}
+
+func TestSelectIterator_streamError(t *testing.T) {
+	// given
+	stream := newStreamMock(awserr.New("InternalError", "internal error", nil))
+	it := newSelectIterator(stream, "\n")
+
+	// when
+	next := it.Next()
+
+	// then
+	assert.False(t, next)
+	assert.Equal(t, "InternalError", it.Err().(Error).Code)
+}
+
+func TestRecordDelimiter(t *testing.T) {
+	assert.Equal(t, "\n", recordDelimiter(&s3.OutputSerialization{JSON: &s3.JSONOutput{}}))
+	assert.Equal(t, "\r\n", recordDelimiter(&s3.OutputSerialization{CSV: &s3.CSVOutput{RecordDelimiter: aws.String("\r\n")}}))
+}
@@ -0,0 +1,379 @@
+package s3
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/pkg/errors"
+)
+
+const (
+	SyncUpload   = "upload"
+	SyncDownload = "download"
+	SyncDelete   = "delete"
+
+	defaultSyncConcurrency = 10
+)
+
+// SyncAction is a single change made, or planned in dry-run mode, by Sync and SyncToLocal.
+type SyncAction struct {
+	Type string
+	Key  string
+	Path string
+}
+
+type SyncConfig struct {
+	deleteExtraneous bool
+	dryRun           bool
+	concurrency      int
+}
+
+// SyncDeleteExtraneous removes files missing on the source side from the destination.
+func SyncDeleteExtraneous() func(*SyncConfig) {
+	return func(c *SyncConfig) {
+		c.deleteExtraneous = true
+	}
+}
+
+// SyncDryRun only returns planned actions without changing anything.
+func SyncDryRun() func(*SyncConfig) {
+	return func(c *SyncConfig) {
+		c.dryRun = true
+	}
+}
+
+func SyncConcurrency(n int) func(*SyncConfig) {
+	return func(c *SyncConfig) {
+		if n > 0 {
+			c.concurrency = n
+		}
+	}
+}
+
+type localFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// Sync uploads files from localDir which are missing or differ under the prefix in the bucket. Files are compared by
+// size, then by MD5 when ETag of the object is an MD5 digest and by modification time otherwise.
+func (c *Client) Sync(localDir, bucket, prefix string, options ...func(*SyncConfig)) ([]*SyncAction, error) {
+	cfg := newSyncConfig(options)
+
+	local, err := listLocalFiles(localDir)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := c.listRemoteObjects(bucket, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := keysFor(local, prefix)
+	var actions []*SyncAction
+	for key, file := range byKey {
+		if obj, ok := remote[key]; !ok || changed(file, obj, true) {
+			actions = append(actions, &SyncAction{Type: SyncUpload, Key: key, Path: file.path})
+		}
+	}
+	if cfg.deleteExtraneous {
+		for key := range remote {
+			if _, ok := byKey[key]; !ok {
+				actions = append(actions, &SyncAction{Type: SyncDelete, Key: key})
+			}
+		}
+	}
+	sortActions(actions)
+
+	if cfg.dryRun {
+		return actions, nil
+	}
+	return actions, runSyncActions(actions, cfg.concurrency, func(a *SyncAction) error {
+		if a.Type == SyncDelete {
+			return c.DeleteObject(bucket, a.Key)
+		}
+		return c.uploadFile(bucket, a.Key, a.Path)
+	})
+}
+
+// SyncToLocal downloads objects under the prefix in the bucket which are missing or differ in localDir, it is the
+// reverse of Sync. Objects are stored as they are, gzip encoded content is not decompressed, and modification time of
+// downloaded files is set to the object's last modification time. Keys resolving outside of localDir fail the sync.
+func (c *Client) SyncToLocal(bucket, prefix, localDir string, options ...func(*SyncConfig)) ([]*SyncAction, error) {
+	cfg := newSyncConfig(options)
+
+	local := make(map[string]*localFile)
+	if _, err := os.Stat(localDir); err == nil {
+		if local, err = listLocalFiles(localDir); err != nil {
+			return nil, err
+		}
+	}
+	remote, err := c.listRemoteObjects(bucket, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := keysFor(local, prefix)
+	var actions []*SyncAction
+	for key, obj := range remote {
+		if strings.HasSuffix(key, "/") {
+			continue
+		}
+		filePath, err := localPath(localDir, strings.TrimPrefix(key, syncPrefix(prefix)))
+		if err != nil {
+			return nil, err
+		}
+		if file, ok := byKey[key]; !ok || changed(file, obj, false) {
+			actions = append(actions, &SyncAction{Type: SyncDownload, Key: key, Path: filePath})
+		}
+	}
+	if cfg.deleteExtraneous {
+		for key, file := range byKey {
+			if _, ok := remote[key]; !ok {
+				actions = append(actions, &SyncAction{Type: SyncDelete, Key: key, Path: file.path})
+			}
+		}
+	}
+	sortActions(actions)
+
+	if cfg.dryRun {
+		return actions, nil
+	}
+	return actions, runSyncActions(actions, cfg.concurrency, func(a *SyncAction) error {
+		if a.Type == SyncDelete {
+			if err := os.Remove(a.Path); err != nil && !os.IsNotExist(err) {
+				return wrapErr(err, "sync remove local file failed")
+			}
+			return nil
+		}
+		return c.downloadFile(bucket, a.Key, a.Path, aws.TimeValue(remote[a.Key].LastModified))
+	})
+}
+
+func (c *Client) uploadFile(bucket, key, filePath string) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return wrapErr(err, "sync open local file failed")
+	}
+	defer f.Close()
+
+	return c.UploadObject(bucket, key, f, func(in *s3manager.UploadInput) {
+		if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+			in.ContentType = aws.String(contentType)
+		}
+	})
+}
+
+// downloadFile writes the object to a temporary file first, so the destination is never left partially written.
+func (c *Client) downloadFile(bucket, key, filePath string, modTime time.Time) error {
+	out, err := c.getObject(&s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}, "sync get object failed", identityEncoding)
+	if err != nil {
+		return err
+	}
+	body := out.Body
+	defer body.Close()
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return wrapErr(err, "sync create local directory failed")
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(filePath), "."+filepath.Base(filePath))
+	if err != nil {
+		return wrapErr(err, "sync create local file failed")
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		if e, ok := err.(Error); ok {
+			return e
+		}
+		return wrapErr(err, "sync download object failed")
+	}
+	if err := tmp.Close(); err != nil {
+		return wrapErr(err, "sync write local file failed")
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return wrapErr(err, "sync write local file failed")
+	}
+	if err := os.Chtimes(filePath, modTime, modTime); err != nil {
+		return wrapErr(err, "sync set local file time failed")
+	}
+	return nil
+}
+
+// identityEncoding stops Go transport from decompressing gzip encoded objects, so the local copy matches the size and
+// ETag of the object and is not downloaded again by the next sync.
+func identityEncoding(r *request.Request) {
+	r.HTTPRequest.Header.Set("Accept-Encoding", "identity")
+}
+
+// localPath joins slash separated relative path of the object to dir and rejects paths resolving outside of dir.
+func localPath(dir, rel string) (string, error) {
+	p := filepath.Join(dir, filepath.FromSlash(rel))
+	r, err := filepath.Rel(filepath.Clean(dir), p)
+	if err != nil || r == ".." || strings.HasPrefix(r, ".."+string(os.PathSeparator)) {
+		return "", wrapErr(errors.Errorf("object path %s is outside of %s", rel, dir), "sync plan download failed")
+	}
+	return p, nil
+}
+
+func (c *Client) listRemoteObjects(bucket, prefix string) (map[string]*ObjectSummary, error) {
+	objects, err := c.ListObjects(bucket, syncPrefix(prefix))
+	if err != nil {
+		return nil, err
+	}
+
+	remote := make(map[string]*ObjectSummary, len(objects))
+	for _, o := range objects {
+		remote[aws.StringValue(o.Key)] = o
+	}
+	return remote, nil
+}
+
+func newSyncConfig(options []func(*SyncConfig)) *SyncConfig {
+	cfg := &SyncConfig{concurrency: defaultSyncConcurrency}
+	for _, opt := range options {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// listLocalFiles returns regular files under dir keyed by slash separated path relative to dir.
+func listLocalFiles(dir string) (map[string]*localFile, error) {
+	files := make(map[string]*localFile)
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = &localFile{path: p, size: info.Size(), modTime: info.ModTime()}
+		return nil
+	})
+	if err != nil {
+		return nil, wrapErr(err, "sync list local files failed")
+	}
+	return files, nil
+}
+
+func keysFor(files map[string]*localFile, prefix string) map[string]*localFile {
+	keys := make(map[string]*localFile, len(files))
+	for rel, f := range files {
+		keys[syncPrefix(prefix)+rel] = f
+	}
+	return keys
+}
+
+func syncPrefix(prefix string) string {
+	if prefix == "" || strings.HasSuffix(prefix, "/") {
+		return prefix
+	}
+	return prefix + "/"
+}
+
+// changed compares sizes, then MD5 digests when the ETag is MD5 of the content and modification times otherwise,
+// in which case only a newer source is considered a change.
+func changed(file *localFile, obj *ObjectSummary, localIsSource bool) bool {
+	if file.size != aws.Int64Value(obj.Size) {
+		return true
+	}
+
+	etag := strings.Trim(aws.StringValue(obj.ETag), `"`)
+	if isMD5Digest(etag) {
+		sum, err := fileMD5(file.path)
+		return err != nil || !strings.EqualFold(etag, sum)
+	}
+
+	if localIsSource {
+		return file.modTime.After(aws.TimeValue(obj.LastModified))
+	}
+	return aws.TimeValue(obj.LastModified).After(file.modTime)
+}
+
+func fileMD5(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := md5.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// sortActions orders transfers before deletes, each sorted by key.
+func sortActions(actions []*SyncAction) {
+	sort.Slice(actions, func(i, j int) bool {
+		if actions[i].Type != actions[j].Type {
+			return actions[i].Type > actions[j].Type
+		}
+		return actions[i].Key < actions[j].Key
+	})
+}
+
+func runSyncActions(actions []*SyncAction, concurrency int, run func(*SyncAction) error) error {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		syncErr error
+		sem     = make(chan struct{}, concurrency)
+	)
+	for _, a := range actions {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(action *SyncAction) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := run(action); err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				if syncErr == nil {
+					syncErr = wrapSyncErr(err, action)
+				}
+			}
+		}(a)
+	}
+	wg.Wait()
+
+	return syncErr
+}
+
+func wrapSyncErr(err error, action *SyncAction) error {
+	e, ok := err.(Error)
+	if !ok {
+		return wrapErr(err, fmt.Sprintf("sync %s of %s failed", action.Type, action.Key))
+	}
+	e.Message = fmt.Sprintf("sync %s of %s failed: %s", action.Type, action.Key, e.Message)
+	return e
+}
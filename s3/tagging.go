@@ -0,0 +1,121 @@
+package s3
+
+import (
+	"net/url"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func (c *Client) GetObjectTagging(bucket, key string) (map[string]string, error) {
+	out, err := c.s3.GetObjectTagging(&s3.GetObjectTaggingInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, wrapErr(err, "get object tagging failed")
+	}
+
+	return fromTagSet(out.TagSet), nil
+}
+
+func (c *Client) PutObjectTagging(bucket, key string, tags map[string]string) error {
+	if _, err := c.s3.PutObjectTagging(&s3.PutObjectTaggingInput{
+		Bucket:  aws.String(bucket),
+		Key:     aws.String(key),
+		Tagging: &s3.Tagging{TagSet: toTagSet(tags)},
+	}); err != nil {
+		return wrapErr(err, "put object tagging failed")
+	}
+
+	return nil
+}
+
+func (c *Client) DeleteObjectTagging(bucket, key string) error {
+	if _, err := c.s3.DeleteObjectTagging(&s3.DeleteObjectTaggingInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}); err != nil {
+		return wrapErr(err, "delete object tagging failed")
+	}
+
+	return nil
+}
+
+func (c *Client) PutObjectACL(bucket, key, acl string) error {
+	if _, err := c.s3.PutObjectAcl(&s3.PutObjectAclInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		ACL:    aws.String(acl),
+	}); err != nil {
+		return wrapErr(err, "put object acl failed")
+	}
+
+	return nil
+}
+
+// TransitionStorageClass changes storage class of an object by copying it in place.
+func (c *Client) TransitionStorageClass(bucket, key, storageClass string) error {
+	return c.CopyObject(bucket, key, bucket, key, CopyStorageClass(storageClass))
+}
+
+func Tagging(tags map[string]string) func(*s3.PutObjectInput) {
+	return func(in *s3.PutObjectInput) {
+		in.Tagging = aws.String(encodeTags(tags))
+	}
+}
+
+func ACL(acl string) func(*s3.PutObjectInput) {
+	return func(in *s3.PutObjectInput) {
+		in.ACL = aws.String(acl)
+	}
+}
+
+func StorageClass(storageClass string) func(*s3.PutObjectInput) {
+	return func(in *s3.PutObjectInput) {
+		in.StorageClass = aws.String(storageClass)
+	}
+}
+
+func CopyACL(acl string) func(*s3.CopyObjectInput) {
+	return func(in *s3.CopyObjectInput) {
+		in.ACL = aws.String(acl)
+	}
+}
+
+func CopyStorageClass(storageClass string) func(*s3.CopyObjectInput) {
+	return func(in *s3.CopyObjectInput) {
+		in.StorageClass = aws.String(storageClass)
+	}
+}
+
+func encodeTags(tags map[string]string) string {
+	values := url.Values{}
+	for k, v := range tags {
+		values.Set(k, v)
+	}
+	return values.Encode()
+}
+
+func fromTagSet(tagSet []*s3.Tag) map[string]string {
+	tags := make(map[string]string, len(tagSet))
+	for _, t := range tagSet {
+		tags[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+	}
+	return tags
+}
+
+func toTagSet(tags map[string]string) []*s3.Tag {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	tagSet := make([]*s3.Tag, 0, len(tags))
+	for _, k := range keys {
+		tagSet = append(tagSet, &s3.Tag{Key: aws.String(k), Value: aws.String(tags[k])})
+	}
+	return tagSet
+}
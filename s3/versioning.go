@@ -0,0 +1,160 @@
+package s3
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+const versionIDParam = "versionId"
+
+type ObjectVersion struct {
+	Key          *string
+	VersionID    *string
+	IsLatest     *bool
+	DeleteMarker *bool
+	ETag         *string
+	Size         *int64
+	LastModified *time.Time
+	StorageClass *string
+}
+
+// ObjectVersionIterator lists object versions and delete markers page by page, ordered by key and from the newest
+// version.
+type ObjectVersionIterator struct {
+	c       *Client
+	input   *s3.ListObjectVersionsInput
+	page    []*ObjectVersion
+	current *ObjectVersion
+	last    bool
+	err     error
+}
+
+func (c *Client) ListObjectVersions(bucket, prefix string) *ObjectVersionIterator {
+	return &ObjectVersionIterator{
+		c: c,
+		input: &s3.ListObjectVersionsInput{
+			Bucket: aws.String(bucket),
+			Prefix: aws.String(prefix),
+		},
+	}
+}
+
+func (it *ObjectVersionIterator) Next() bool {
+	for len(it.page) == 0 {
+		if it.last || it.err != nil {
+			it.current = nil
+			return false
+		}
+		it.fetch()
+	}
+
+	it.current, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+func (it *ObjectVersionIterator) Version() *ObjectVersion {
+	return it.current
+}
+
+func (it *ObjectVersionIterator) Err() error {
+	return it.err
+}
+
+func (it *ObjectVersionIterator) fetch() {
+	out, err := it.c.s3.ListObjectVersions(it.input)
+	if err != nil {
+		it.err = wrapErr(err, "list object versions failed")
+		return
+	}
+
+	for _, v := range out.Versions {
+		it.page = append(it.page, &ObjectVersion{
+			Key:          v.Key,
+			VersionID:    v.VersionId,
+			IsLatest:     v.IsLatest,
+			DeleteMarker: aws.Bool(false),
+			ETag:         v.ETag,
+			Size:         v.Size,
+			LastModified: v.LastModified,
+			StorageClass: v.StorageClass,
+		})
+	}
+	for _, m := range out.DeleteMarkers {
+		it.page = append(it.page, &ObjectVersion{
+			Key:          m.Key,
+			VersionID:    m.VersionId,
+			IsLatest:     m.IsLatest,
+			DeleteMarker: aws.Bool(true),
+			LastModified: m.LastModified,
+		})
+	}
+	sortVersions(it.page)
+
+	it.last = !aws.BoolValue(out.IsTruncated)
+	it.input.KeyMarker = out.NextKeyMarker
+	it.input.VersionIdMarker = out.NextVersionIdMarker
+}
+
+// RestoreVersion makes a prior version of the object current again by copying it over the latest one.
+func (c *Client) RestoreVersion(bucket, key, versionID string, options ...func(*s3.CopyObjectInput)) error {
+	return c.CopyObject(bucket, key, bucket, key, append(options, CopySourceVersionID(versionID))...)
+}
+
+func GetVersionID(versionID string) func(*s3.GetObjectInput) {
+	return func(in *s3.GetObjectInput) {
+		in.VersionId = aws.String(versionID)
+	}
+}
+
+func HeadVersionID(versionID string) func(*s3.HeadObjectInput) {
+	return func(in *s3.HeadObjectInput) {
+		in.VersionId = aws.String(versionID)
+	}
+}
+
+// DeleteVersionID permanently deletes the given version instead of adding a delete marker.
+func DeleteVersionID(versionID string) func(*s3.DeleteObjectInput) {
+	return func(in *s3.DeleteObjectInput) {
+		in.VersionId = aws.String(versionID)
+	}
+}
+
+func CopySourceVersionID(versionID string) func(*s3.CopyObjectInput) {
+	return func(in *s3.CopyObjectInput) {
+		source := strings.SplitN(aws.StringValue(in.CopySource), "?", 2)[0]
+		in.CopySource = aws.String(source + "?" + versionIDParam + "=" + url.QueryEscape(versionID))
+	}
+}
+
+func copySourceVersionID(copySource *string) *string {
+	parts := strings.SplitN(aws.StringValue(copySource), "?", 2)
+	if len(parts) != 2 {
+		return nil
+	}
+
+	query, err := url.ParseQuery(parts[1])
+	if err != nil || query.Get(versionIDParam) == "" {
+		return nil
+	}
+	return aws.String(query.Get(versionIDParam))
+}
+
+// sortVersions merges versions and delete markers of a single page, S3 returns each list ordered by key and from the
+// newest version. LastModified has a second precision, so ties are broken by IsLatest and then by the listing order.
+func sortVersions(versions []*ObjectVersion) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		ki, kj := aws.StringValue(versions[i].Key), aws.StringValue(versions[j].Key)
+		if ki != kj {
+			return ki < kj
+		}
+		if li, lj := aws.BoolValue(versions[i].IsLatest), aws.BoolValue(versions[j].IsLatest); li != lj {
+			return li
+		}
+		return aws.TimeValue(versions[i].LastModified).After(aws.TimeValue(versions[j].LastModified))
+	})
+}
@@ -0,0 +1,65 @@
+// +build local ci
+
+package s3
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopySourceVersionID_ok(t *testing.T) {
+	// given
+	input := &s3.CopyObjectInput{CopySource: aws.String(copySource("bucket", "dir/some key"))}
+
+	// when
+	CopySourceVersionID("v+1")(input)
+
+	// then
+	assert.Equal(t, "bucket/dir/some%20key?versionId=v%2B1", *input.CopySource)
+	assert.Equal(t, "v+1", *copySourceVersionID(input.CopySource))
+	assert.Nil(t, copySourceVersionID(aws.String("bucket/key")))
+}
+
+func TestSortVersions_ok(t *testing.T) {
+	// given
+	now := time.Now()
+	versions := []*ObjectVersion{
+		{Key: aws.String("a"), VersionID: aws.String("1"), LastModified: aws.Time(now.Add(-time.Hour))},
+		{Key: aws.String("b"), VersionID: aws.String("2"), LastModified: aws.Time(now)},
+		{Key: aws.String("a"), VersionID: aws.String("3"), LastModified: aws.Time(now), DeleteMarker: aws.Bool(true)},
+	}
+
+	// when
+	sortVersions(versions)
+
+	// then
+	var ids []string
+	for _, v := range versions {
+		ids = append(ids, *v.VersionID)
+	}
+	assert.Equal(t, []string{"3", "1", "2"}, ids)
+}
+
+func TestSortVersions_sameTime(t *testing.T) {
+	// given versions listed before delete markers, all modified within the same second
+	now := time.Now()
+	versions := []*ObjectVersion{
+		{Key: aws.String("a"), VersionID: aws.String("2"), IsLatest: aws.Bool(false), LastModified: aws.Time(now)},
+		{Key: aws.String("a"), VersionID: aws.String("1"), IsLatest: aws.Bool(false), LastModified: aws.Time(now)},
+		{Key: aws.String("a"), VersionID: aws.String("3"), IsLatest: aws.Bool(true), LastModified: aws.Time(now)},
+	}
+
+	// when
+	sortVersions(versions)
+
+	// then
+	var ids []string
+	for _, v := range versions {
+		ids = append(ids, *v.VersionID)
+	}
+	assert.Equal(t, []string{"3", "2", "1"}, ids)
+}